@@ -0,0 +1,393 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+
+	"github.com/dotcommander/syn/internal/app"
+	"github.com/dotcommander/syn/internal/config"
+	"github.com/dotcommander/syn/internal/keyring"
+)
+
+// passphraseEnv supplies the encrypted file's passphrase without a prompt.
+const passphraseEnv = "SYN_KEY_PASSPHRASE"
+
+// Values of --store.
+const (
+	storeAuto    = "auto"
+	storeKeyring = "keyring"
+	storeFile    = "file"
+)
+
+// sourceLocked is the api.key source config show reports for a stored key
+// that could not be read.
+const sourceLocked config.Source = "locked"
+
+var authStore string //nolint:gochecknoglobals // cobra flag binding
+
+// storedKeySource names the store api.key was read from, or "" when it came
+// from flags, the environment or the config file.
+var storedKeySource string //nolint:gochecknoglobals // set once per run in initConfig
+
+// storedKeyErr is why a stored key could not be read for a command that
+// does not need one, such as an encrypted file without SYN_KEY_PASSPHRASE.
+var storedKeyErr error //nolint:gochecknoglobals // set once per run in initConfig
+
+var authCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "auth",
+	Short: "Store the API key in the keyring or an encrypted file",
+	Long: `Keep the API key out of config.yaml and the environment.
+
+login stores the key in the Secret Service keyring (GNOME Keyring, KWallet)
+through secret-tool. Without secret-tool, or with --store file, it is written
+to ~/.config/syn/credentials/<account>.age, encrypted with a passphrase that
+is prompted for or read from SYN_KEY_PASSPHRASE.
+
+Keys are stored per profile; the account is "profile-<name>" for the active
+profile, or "syn" without one. A key from flags, SYN_API_KEY or config.yaml
+takes precedence over a stored one; syn reads the keyring, then the encrypted
+file, only when none is set.
+
+Examples:
+  syn auth login
+  echo "$KEY" | syn auth login --store file
+  syn --profile work auth login
+  syn auth status
+  syn auth logout`,
+}
+
+var authLoginCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "login",
+	Short: "Store an API key",
+	Long: `Read an API key (hidden prompt, or stdin when piped), check it against
+the API, and store it for the active profile.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAuthLogin(cmd.Context())
+	},
+}
+
+var authStatusCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "status",
+	Short: "Show where the API key comes from",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAuthStatus()
+	},
+}
+
+var authLogoutCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "logout",
+	Short: "Remove the stored API key",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAuthLogout()
+	},
+}
+
+func init() { //nolint:gochecknoinits // cobra command registration
+	rootCmd.AddCommand(authCmd)
+	authCmd.AddCommand(authLoginCmd)
+	authCmd.AddCommand(authStatusCmd)
+	authCmd.AddCommand(authLogoutCmd)
+
+	authLoginCmd.Flags().StringVar(&authStore, "store", storeAuto, "where to store the key: auto, keyring or file")
+}
+
+// keyAccount returns the account keys are stored under: "profile-<name>" for
+// the active profile, or "syn" without one. The prefix keeps a profile named
+// like the default account from sharing its key.
+func keyAccount() string {
+	if profile := activeProfile(); profile != "" {
+		return "profile-" + profile
+	}
+	return "syn"
+}
+
+// passphrase returns a passphrase source for the encrypted file: the
+// SYN_KEY_PASSPHRASE variable, or a hidden prompt when prompt is set and
+// stdin is a terminal. confirm asks twice, for new files.
+func passphrase(prompt, confirm bool) func() (string, error) {
+	return func() (string, error) {
+		if pass := os.Getenv(passphraseEnv); pass != "" {
+			return pass, nil
+		}
+		fd := int(os.Stdin.Fd()) //nolint:gosec // file descriptors fit in int
+		if !prompt || !term.IsTerminal(fd) {
+			return "", fmt.Errorf("the API key file needs a passphrase; set %s or run in a terminal", passphraseEnv)
+		}
+		pass, err := readHidden(fd, "Passphrase for "+keyFile(false, false).Path(keyAccount())+":")
+		if err != nil {
+			return "", err
+		}
+		if pass == "" {
+			return "", errors.New("empty passphrase")
+		}
+		if confirm {
+			again, err := readHidden(fd, "Repeat passphrase:")
+			if err != nil {
+				return "", err
+			}
+			if again != pass {
+				return "", errors.New("passphrases do not match")
+			}
+		}
+		return pass, nil
+	}
+}
+
+// readHidden prompts on stderr and reads a line from the terminal without echo.
+func readHidden(fd int, prompt string) (string, error) {
+	fmt.Fprintf(os.Stderr, "%s ", theme.UserPrompt.Render(prompt))
+	data, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// keyFile returns the encrypted-file store.
+func keyFile(prompt, confirm bool) *keyring.File {
+	return keyring.NewFile(keyring.DefaultDir(), passphrase(prompt, confirm))
+}
+
+// keyStores returns the stores api.key is resolved from, in order.
+func keyStores(prompt bool) []keyring.Store {
+	var stores []keyring.Store
+	if ss := keyring.NewSecretService(); ss.Available() {
+		stores = append(stores, ss)
+	}
+	return append(stores, keyFile(prompt, false))
+}
+
+// resolveStoredKey fills api.key from the keyring or the encrypted file when
+// flags, the environment and the config file leave it empty. Only commands
+// that need the key may prompt for the file's passphrase or fail on a store
+// error; the rest carry on without it, noting the error in storedKeyErr.
+func resolveStoredKey(cmd *cobra.Command) error {
+	if viper.GetString("api.key") != "" || replaying() || isSubcommand(cmd, authCmd) {
+		return nil
+	}
+	required := needsAPIKey(cmd)
+	secret, store, err := keyring.Lookup(keyAccount(), keyStores(required)...)
+	if err != nil {
+		if errors.Is(err, keyring.ErrNotFound) {
+			return nil
+		}
+		if required {
+			return fmt.Errorf("read stored API key: %w", err)
+		}
+		storedKeyErr = err
+		return nil
+	}
+	viper.Set("api.key", secret)
+	storedKeySource = store.Name()
+	return nil
+}
+
+// lockedKeyNote explains a stored key left unread, or returns "".
+func lockedKeyNote() string {
+	if storedKeyErr == nil {
+		return ""
+	}
+	return fmt.Sprintf("stored API key is locked: %v", storedKeyErr)
+}
+
+// configuredKey returns the API key set by a flag, the environment or the
+// config file, and where it came from.
+func configuredKey() config.Setting {
+	s := config.Lookup(viper.GetViper(), "api.key", activeProfile(), flagSet)
+	if s.Value == nil || s.Value == "" {
+		s.Source = config.SourceDefault
+	}
+	return s
+}
+
+// readAPIKey reads the key to store: a hidden prompt on a terminal, or all
+// of stdin when piped.
+func readAPIKey() (string, error) {
+	fd := int(os.Stdin.Fd()) //nolint:gosec // file descriptors fit in int
+	if term.IsTerminal(fd) {
+		return readHidden(fd, "API key:")
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read stdin: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func runAuthLogin(parent context.Context) error {
+	switch authStore {
+	case storeAuto, storeKeyring, storeFile:
+	default:
+		return fmt.Errorf("invalid --store %q (use auto, keyring or file)", authStore)
+	}
+	if authStore == storeKeyring && !keyring.NewSecretService().Available() {
+		return errors.New("secret-tool not found; install libsecret-tools or use --store file")
+	}
+
+	key, err := readAPIKey()
+	if err != nil {
+		return err
+	}
+	if key == "" {
+		return errors.New("no API key given")
+	}
+
+	viper.Set("api.key", key)
+	ctx, cancel := context.WithTimeout(parent, 30*time.Second)
+	defer cancel()
+	if _, err := servedModels(ctx); errors.Is(err, app.ErrAuth) {
+		return fmt.Errorf("the API rejected this key: %w", err)
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "%s could not verify the key: %v\n", theme.ErrorText.Render("Warning:"), err)
+	}
+
+	account := keyAccount()
+	where, err := storeKey(account, key, authStore)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s API key for %s in %s\n", theme.SuccessText.Render("Stored"), account, where)
+	if s := configuredKey(); s.Source != config.SourceDefault {
+		fmt.Println(theme.Dim.Render(fmt.Sprintf("  Note: the key from %s takes precedence; remove it to use the stored one.", s.Source)))
+	}
+	return nil
+}
+
+// storeKey saves key for account in the keyring, or in the encrypted file
+// when mode is storeFile or the keyring is unavailable or fails under
+// storeAuto. It returns where the key went.
+func storeKey(account, key, mode string) (string, error) {
+	if ss := keyring.NewSecretService(); mode != storeFile && ss.Available() {
+		err := ss.Set(account, key)
+		if err == nil {
+			return "the keyring", nil
+		}
+		if mode == storeKeyring {
+			return "", err
+		}
+		fmt.Fprintf(os.Stderr, "%s %v; using the encrypted file\n", theme.ErrorText.Render("Warning:"), err)
+	}
+	f := keyFile(true, true)
+	if err := f.Set(account, key); err != nil {
+		return "", err
+	}
+	return f.Path(account), nil
+}
+
+// authSource is one place an API key can come from, as reported by
+// syn auth status.
+type authSource struct {
+	Source string `json:"source"`
+	Set    bool   `json:"set"`
+	Active bool   `json:"active"`
+	Value  string `json:"value,omitempty"` // masked
+	Detail string `json:"detail,omitempty"`
+}
+
+func runAuthStatus() error {
+	account := keyAccount()
+	configured := configuredKey()
+
+	sources := []authSource{{Source: "config", Detail: "flag, SYN_API_KEY or config.yaml"}}
+	if configured.Source != config.SourceDefault {
+		sources[0].Set = true
+		sources[0].Value = fmt.Sprint(config.Mask("api.key", configured.Value))
+		sources[0].Detail = string(configured.Source)
+	}
+
+	ss := keyring.NewSecretService()
+	kr := authSource{Source: keyring.NameKeyring}
+	if !ss.Available() {
+		kr.Detail = "secret-tool not installed"
+	} else if secret, err := ss.Get(account); err == nil {
+		kr.Set = true
+		kr.Value = fmt.Sprint(config.Mask("api.key", secret))
+	} else if !errors.Is(err, keyring.ErrNotFound) {
+		kr.Detail = err.Error()
+	}
+	sources = append(sources, kr)
+
+	f := keyFile(false, false)
+	sources = append(sources, authSource{Source: keyring.NameFile, Set: f.Exists(account), Detail: f.Path(account)})
+
+	for i := range sources {
+		if sources[i].Set {
+			sources[i].Active = true
+			break
+		}
+	}
+
+	if viper.GetBool("json") {
+		return printJSON(struct {
+			Account string       `json:"account"`
+			Sources []authSource `json:"sources"`
+		}{account, sources})
+	}
+
+	fmt.Println()
+	fmt.Println(theme.Section.Render("API key for " + account))
+	fmt.Println(theme.Divider.Render(strings.Repeat("-", 50)))
+	fmt.Println()
+	for _, s := range sources {
+		marker := "  "
+		if s.Active {
+			marker = theme.SuccessText.Render("* ")
+		}
+		state := "not set"
+		if s.Set {
+			state = s.Value
+			if state == "" {
+				state = "stored (encrypted)"
+			}
+		}
+		fmt.Printf("  %s%s  %-18s  %s\n", marker,
+			theme.Command.Render(fmt.Sprintf("%-14s", s.Source)), state, theme.Dim.Render(s.Detail))
+	}
+	fmt.Println()
+	return nil
+}
+
+func runAuthLogout() error {
+	account := keyAccount()
+	stores := []keyring.Store{keyFile(false, false)}
+	if ss := keyring.NewSecretService(); ss.Available() {
+		stores = []keyring.Store{ss, stores[0]}
+	}
+
+	removed := 0
+	for _, s := range stores {
+		err := s.Delete(account)
+		switch {
+		case err == nil:
+			removed++
+			where := "the keyring"
+			if f, ok := s.(*keyring.File); ok {
+				where = f.Path(account)
+			}
+			fmt.Printf("%s API key for %s from %s\n", theme.SuccessText.Render("Removed"), account, where)
+		case !errors.Is(err, keyring.ErrNotFound):
+			return err
+		}
+	}
+	if removed == 0 {
+		fmt.Println(theme.Dim.Render("No stored API key for " + account + "."))
+	}
+	if s := configuredKey(); s.Source != config.SourceDefault {
+		fmt.Println(theme.Dim.Render(fmt.Sprintf("  Note: a key from %s is still set.", s.Source)))
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/dotcommander/syn/internal/app"
+	"github.com/dotcommander/syn/internal/bench"
+)
+
+var ( //nolint:gochecknoglobals // cobra flag bindings require package-level vars
+	benchModelsCSV  string
+	benchSizesCSV   string
+	benchConcCSV    string
+	benchRequests   int
+	benchMaxTokens  int
+	benchHistory    string
+	benchNoHistory  bool
+	benchReqTimeout time.Duration
+)
+
+var modelBenchCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "bench",
+	Short: "Benchmark model latency and throughput",
+	Long: `Send a fixed set of streamed prompts at several input sizes and
+concurrency levels, and report time to first token (TTFT), generation speed
+(tok/s) and error rate with p50/p95/p99 for each model.
+
+Sizes: small (~128 tokens), medium (~2k), large (~16k).
+
+Examples:
+  syn model bench
+  syn model bench --models kimi,glm --sizes small,large --concurrency 1,8
+  syn model bench --requests 20 --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runModelBench(cmd.Context())
+	},
+}
+
+func init() { //nolint:gochecknoinits // cobra command registration
+	modelCmd.AddCommand(modelBenchCmd)
+	modelBenchCmd.Flags().StringVar(&benchModelsCSV, "models", "", "comma-separated model IDs or aliases (default: api.model)")
+	modelBenchCmd.Flags().StringVar(&benchSizesCSV, "sizes", "small,medium", "comma-separated prompt sizes: small, medium, large")
+	modelBenchCmd.Flags().StringVar(&benchConcCSV, "concurrency", "1,4", "comma-separated concurrency levels")
+	modelBenchCmd.Flags().IntVar(&benchRequests, "requests", 10, "requests per model, size and concurrency level")
+	modelBenchCmd.Flags().IntVar(&benchMaxTokens, "max-tokens", 256, "completion token cap per request")
+	modelBenchCmd.Flags().DurationVar(&benchReqTimeout, "timeout", 2*time.Minute, "per-request timeout")
+	modelBenchCmd.Flags().StringVar(&benchHistory, "history", "analysis-results/bench-history.jsonl", "jsonl file for appending benchmark results")
+	modelBenchCmd.Flags().BoolVar(&benchNoHistory, "no-history", false, "do not append results to the history file")
+}
+
+func runModelBench(parent context.Context) error {
+	cfg, err := benchConfig()
+	if err != nil {
+		return err
+	}
+	humanOutput := !viper.GetBool("json")
+
+	if humanOutput {
+		cells := len(cfg.Models) * len(cfg.Sizes) * len(cfg.Concurrency)
+		fmt.Println()
+		fmt.Println(theme.Section.Render(fmt.Sprintf("Benchmarking %d models (%d cells, %d requests each)", len(cfg.Models), cells, cfg.Requests)))
+		fmt.Println(theme.Divider.Render(strings.Repeat("-", 60)))
+		cfg.Progress = func(r bench.Row) {
+			fmt.Printf("  %s %s c=%d ttft_p50=%.0fms tok/s_p50=%.1f errors=%d/%d\n",
+				theme.Command.Render(r.Model), r.Size, r.Concurrency, r.TTFTMS.P50, r.TokensPerSec.P50, r.Errors, r.Requests)
+		}
+	}
+
+	report, err := bench.Run(parent, benchStream(newClient()), cfg)
+	if err != nil {
+		return err
+	}
+
+	if !benchNoHistory && strings.TrimSpace(benchHistory) != "" {
+		if err := bench.AppendHistory(benchHistory, report); err != nil {
+			return err
+		}
+	}
+
+	if !humanOutput {
+		return printJSON(report)
+	}
+	fmt.Println()
+	fmt.Print(bench.RenderTable(report))
+	fmt.Println()
+	for _, r := range report.Rows {
+		if r.FirstError != "" {
+			fmt.Fprintf(os.Stderr, "%s %s (%s, c=%d): %s\n",
+				theme.ErrorText.Render("Error:"), r.Model, r.Size, r.Concurrency, r.FirstError)
+		}
+	}
+	if !benchNoHistory && strings.TrimSpace(benchHistory) != "" {
+		fmt.Println(theme.Dim.Render("Appended results to " + benchHistory))
+	}
+	return nil
+}
+
+// benchConfig parses the bench flags.
+func benchConfig() (bench.Config, error) {
+	cfg := bench.Config{Requests: benchRequests, MaxTokens: benchMaxTokens, Timeout: benchReqTimeout}
+
+	for _, m := range splitCSV(benchModelsCSV) {
+		cfg.Models = append(cfg.Models, app.ResolveModel(m))
+	}
+	if len(cfg.Models) == 0 {
+		m := viper.GetString("model")
+		if m == "" {
+			m = viper.GetString("api.model")
+		}
+		cfg.Models = []string{app.ResolveModel(m)}
+	}
+
+	for _, name := range splitCSV(benchSizesCSV) {
+		size, ok := bench.LookupSize(name)
+		if !ok {
+			return bench.Config{}, fmt.Errorf("unknown size %q (expected small, medium or large)", name)
+		}
+		cfg.Sizes = append(cfg.Sizes, size)
+	}
+
+	for _, s := range splitCSV(benchConcCSV) {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return bench.Config{}, fmt.Errorf("invalid concurrency %q", s)
+		}
+		cfg.Concurrency = append(cfg.Concurrency, n)
+	}
+	return cfg, nil
+}
+
+// benchStream adapts ChatStream to bench.StreamFunc.
+func benchStream(client *app.Client) bench.StreamFunc {
+	return func(ctx context.Context, model, prompt string, maxTokens int) (bench.Sample, error) {
+		opts := app.DefaultChatOptions()
+		opts.Model = model
+		opts.MaxTokens = app.IntPtr(maxTokens)
+		opts.NoFallback = true // measure the named model only
+		opts.NoRetry = true    // count every failure
+		opts.NoCache = true    // always hit the API
+
+		started := time.Now()
+		sr, err := client.ChatStream(ctx, prompt, opts)
+		if err != nil {
+			return bench.Sample{}, err
+		}
+		tokens := sr.Usage.CompletionTokens
+		if tokens == 0 {
+			tokens = len(sr.Content) / 4 // provider sent no usage; estimate
+		}
+		return bench.Sample{
+			TTFT:             time.Duration(sr.TTFMS) * time.Millisecond,
+			Total:            time.Since(started) - time.Duration(sr.WaitMS)*time.Millisecond,
+			CompletionTokens: tokens,
+		}, nil
+	}
+}
+
+func splitCSV(s string) []string {
+	var out []string
+	for part := range strings.SplitSeq(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/dotcommander/syn/internal/app"
+	"github.com/dotcommander/syn/internal/cache"
+	"github.com/dotcommander/syn/internal/textfile"
+)
+
+var cacheCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "cache",
+	Short: "Inspect and clear the response caches",
+	Long: `Inspect and clear syn's on-disk caches.
+
+responses  chat completions and per-input embeddings, keyed by a hash of the
+           model, messages and sampling params (opt-in: cache.enabled)
+search     web search results per normalized query (search.cache_ttl)
+
+Use --refresh on any command to skip cached entries and store fresh ones, or
+--no-cache to neither read nor write the caches.
+
+Examples:
+  syn cache stats
+  syn cache clear
+  syn cache clear search`,
+}
+
+var cacheStatsCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "stats",
+	Short: "Show entries, size and age per cache",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCacheStats()
+	},
+}
+
+var cacheClearCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:       "clear [responses|search]",
+	Short:     "Remove cached entries",
+	Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: []string{"responses", "search"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCacheClear(args)
+	},
+}
+
+func init() { //nolint:gochecknoinits // cobra command registration
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheStatsCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+}
+
+// namedCache is a cache store with its namespace and whether it is in use.
+type namedCache struct {
+	name    string
+	enabled bool
+	store   *cache.Cache
+}
+
+// caches returns every cache store, including disabled ones, so stats and
+// clear reach entries written while a cache was enabled.
+func caches() ([]namedCache, error) {
+	responses, err := openCache("cache.dir", "responses", viper.GetDuration("cache.ttl"))
+	if err != nil {
+		return nil, err
+	}
+	search, err := openCache("search.cache_dir", "search", viper.GetDuration("search.cache_ttl"))
+	if err != nil {
+		return nil, err
+	}
+	return []namedCache{
+		{name: "responses", enabled: viper.GetBool("cache.enabled"), store: responses},
+		{name: "search", enabled: viper.GetDuration("search.cache_ttl") > 0, store: search},
+	}, nil
+}
+
+// openCache opens the store whose directory is configured at dirKey,
+// defaulting to the user cache dir for namespace.
+func openCache(dirKey, namespace string, ttl time.Duration) (*cache.Cache, error) {
+	dir := viper.GetString(dirKey)
+	if dir == "" {
+		var err error
+		if dir, err = cache.DefaultDir(namespace); err != nil {
+			return nil, err
+		}
+	}
+	store := cache.New(dir, ttl)
+	store.SetMaxBytes(viper.GetInt64("cache.max_bytes"))
+	return store, nil
+}
+
+// newResponseCache returns the chat/embedding cache, or nil when it is disabled.
+func newResponseCache() *cache.Cache {
+	if !viper.GetBool("cache.enabled") {
+		return nil
+	}
+	store, err := openCache("cache.dir", "responses", viper.GetDuration("cache.ttl"))
+	if err != nil {
+		return nil
+	}
+	return store
+}
+
+// newSearchCache returns the configured search cache, or nil when caching is disabled.
+func newSearchCache() *cache.Cache {
+	ttl := viper.GetDuration("search.cache_ttl")
+	if ttl <= 0 {
+		return nil
+	}
+	store, err := openCache("search.cache_dir", "search", ttl)
+	if err != nil {
+		return nil
+	}
+	return store
+}
+
+// cacheMode maps --no-cache and --refresh to an app.CacheMode. Recording
+// and replaying bypass the caches so every request reaches the transport.
+func cacheMode() app.CacheMode {
+	switch {
+	case viper.GetBool("no_cache"), recording():
+		return app.CacheOff
+	case viper.GetBool("refresh"):
+		return app.CacheRefresh
+	}
+	return app.CacheOn
+}
+
+type cacheStatsOutput struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	TTL     string `json:"ttl"`
+	cache.Stats
+}
+
+func runCacheStats() error {
+	all, err := caches()
+	if err != nil {
+		return err
+	}
+	out := make([]cacheStatsOutput, 0, len(all))
+	for _, c := range all {
+		stats, err := c.store.Stats()
+		if err != nil {
+			return err
+		}
+		out = append(out, cacheStatsOutput{Name: c.name, Enabled: c.enabled, TTL: c.store.TTL().String(), Stats: stats})
+	}
+
+	if viper.GetBool("json") {
+		return printJSON(out)
+	}
+
+	fmt.Println()
+	fmt.Println(theme.Section.Render("Caches"))
+	fmt.Println(theme.Divider.Render(strings.Repeat("-", 50)))
+	fmt.Println()
+	for _, s := range out {
+		state := "enabled"
+		if !s.Enabled {
+			state = "disabled"
+		}
+		fmt.Printf("  %s  %s\n", theme.Command.Render(fmt.Sprintf("%-10s", s.Name)),
+			theme.Dim.Render(fmt.Sprintf("%s, ttl %s, %s", state, s.TTL, s.Dir)))
+		detail := fmt.Sprintf("%d entries, %s", s.Entries, textfile.FormatSize(s.Bytes))
+		if s.Expired > 0 {
+			detail += fmt.Sprintf(", %d expired", s.Expired)
+		}
+		if s.Entries > 0 {
+			detail += fmt.Sprintf(", newest %s", s.Newest.Format("2006-01-02 15:04"))
+		}
+		fmt.Printf("  %-10s  %s\n", "", theme.Description.Render(detail))
+	}
+	fmt.Println()
+	return nil
+}
+
+func runCacheClear(args []string) error {
+	all, err := caches()
+	if err != nil {
+		return err
+	}
+	cleared := map[string]int{}
+	for _, c := range all {
+		if len(args) > 0 && !slices.Contains(args, c.name) {
+			continue
+		}
+		n, err := c.store.Clear()
+		if err != nil {
+			return err
+		}
+		cleared[c.name] = n
+	}
+
+	if viper.GetBool("json") {
+		return printJSON(cleared)
+	}
+	for _, c := range all {
+		if n, ok := cleared[c.name]; ok {
+			fmt.Println(theme.SuccessText.Render(fmt.Sprintf("Cleared %d %s entries", n, c.name)))
+		}
+	}
+	return nil
+}
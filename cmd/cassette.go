@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/spf13/viper"
+
+	"github.com/dotcommander/syn/internal/app"
+	"github.com/dotcommander/syn/internal/cassette"
+)
+
+// replayAPIKey stands in for the API key when replaying without one configured.
+const replayAPIKey = "replay"
+
+// httpDoer is the transport set up by --record or --replay, shared by every
+// client so repeated requests are numbered across the whole run.
+var httpDoer app.HTTPDoer //nolint:gochecknoglobals // set once per run in initConfig
+
+// replaying reports whether --replay is set.
+func replaying() bool {
+	return viper.GetString("replay") != ""
+}
+
+// recording reports whether --record or --replay is set; both bypass the caches.
+func recording() bool {
+	return replaying() || viper.GetString("record") != ""
+}
+
+// setupCassette builds httpDoer from --record or --replay. Replay needs no
+// API key, so an empty one is replaced with a placeholder.
+func setupCassette() error {
+	record, replay := viper.GetString("record"), viper.GetString("replay")
+	switch {
+	case record != "" && replay != "":
+		return errors.New("--record and --replay cannot be used together")
+	case replay != "":
+		if viper.GetString("api.key") == "" {
+			viper.Set("api.key", replayAPIKey)
+		}
+		r, err := cassette.NewReplayer(replay)
+		if err != nil {
+			return err
+		}
+		httpDoer = r
+	case record != "":
+		r, err := cassette.NewRecorder(record, &http.Client{Timeout: app.DefaultTimeout}, viper.GetString("api.key"))
+		if err != nil {
+			return err
+		}
+		httpDoer = r
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"sync/atomic"
 	"syscall"
@@ -23,18 +24,55 @@ var chatCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registr
 	Short: "Start interactive chat session",
 	Long: `Interactive REPL with conversation context.
 
+With --index, each turn retrieves the most relevant chunks from a local
+index (see syn index) and cites them as [n].
+
+/image attaches an image to your next message. Local files are encoded once
+when attached and the conversation keeps the encoded image, so follow-up
+questions can refer back to it even if the file is moved. Turns with
+images use a vision-capable model unless -m is set.
+
 Commands:
-  /clear  - Clear conversation history
-  /model  - Show current model
-  /exit   - Exit chat session
-  /help   - Show help`,
+  /clear    - Clear conversation history
+  /model    - Show current model
+  /image    - Attach an image (path or URL) to the next message
+  /sources  - Show sources used for the last answer
+  /exit     - Exit chat session
+  /help     - Show help`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runInteractiveChat()
 	},
 }
 
+var chatIndex string //nolint:gochecknoglobals // cobra flag binding
+
 func init() { //nolint:gochecknoinits // cobra command registration
 	rootCmd.AddCommand(chatCmd)
+	chatCmd.Flags().StringVar(&chatIndex, "index", "", "retrieve context for each turn from a local index")
+}
+
+// chatSession holds REPL state shared with slash commands.
+type chatSession struct {
+	context   []app.Message
+	sources   []app.Source // sources retrieved for the last turn
+	retriever *retriever   // nil unless --index is set
+	images    []attachment // images attached to the next turn
+}
+
+// attachment is an image queued with /image. Local files are encoded when
+// attached, so the history does not depend on the file staying in place.
+type attachment struct {
+	name string // path or URL as given, for display
+	url  string // remote URL or prepared data: URL
+}
+
+// attachmentURLs returns the URLs of images.
+func attachmentURLs(images []attachment) []string {
+	urls := make([]string, 0, len(images))
+	for _, img := range images {
+		urls = append(urls, img.url)
+	}
+	return urls
 }
 
 // animateThinking displays an animated spinner while waiting for API response.
@@ -64,11 +102,20 @@ func runInteractiveChat() error {
 
 	client := newClient()
 	baseOpts := app.DefaultChatOptions()
-	baseOpts.FilePath = viper.GetString("file")
+	baseOpts.Files = inputFiles()
+	baseOpts.Pages = pagesFlag
 
-	var conversationContext []app.Message
+	session := &chatSession{}
 	maxContextMessages := 20
 
+	if chatIndex != "" {
+		r, err := newRetriever(chatIndex, client)
+		if err != nil {
+			return err
+		}
+		session.retriever = r
+	}
+
 	printWelcomeBanner()
 
 	scanner := bufio.NewScanner(os.Stdin)
@@ -92,27 +139,55 @@ func runInteractiveChat() error {
 		}
 
 		if strings.HasPrefix(input, "/") {
-			if handleChatCommand(input, &conversationContext) {
+			if handleChatCommand(input, session, client) {
 				continue
 			}
 		}
 
-		opts := buildChatOpts(baseOpts, conversationContext)
-		response, err := sendWithSpinner(ctx, client, input, opts)
+		opts := buildChatOpts(baseOpts, session.context)
+		opts.Images = attachmentURLs(session.images)
+		if session.retriever != nil {
+			sources, err := session.retriever.Retrieve(ctx, input)
+			if err != nil {
+				fmt.Println(theme.ErrorText.Render("Error: ") + theme.Dim.Render(err.Error()))
+				fmt.Println()
+				continue
+			}
+			session.sources = sources
+			opts.Sources = sources
+		}
+
+		response, usage, err := sendWithSpinner(ctx, client, input, opts)
 		if err != nil {
 			fmt.Println(theme.ErrorText.Render("Error: ") + theme.Dim.Render(err.Error()))
 			fmt.Println()
 			continue
 		}
 
-		conversationContext = appendExchange(conversationContext, input, response, maxContextMessages)
+		session.context = appendExchange(session.context, userMessage(input, opts.Images), response, maxContextMessages)
+		session.images = nil
 
 		fmt.Println()
 		fmt.Printf("%s %s\n", theme.AssistantPrompt.Render("syn>"), response)
+		if usage.FallbackFrom != "" {
+			fmt.Println(theme.Dim.Render(fmt.Sprintf("  answered by %s; %s was unavailable", usage.Model, usage.FallbackFrom)))
+		}
+		if session.retriever != nil {
+			fmt.Println(theme.Dim.Render("  sources: " + sourceRefs(session.sources)))
+		}
 		fmt.Println()
 	}
 }
 
+// sourceRefs formats sources as a compact "[1] ref, [2] ref" line.
+func sourceRefs(sources []app.Source) string {
+	refs := make([]string, 0, len(sources))
+	for i, s := range sources {
+		refs = append(refs, fmt.Sprintf("[%d] %s", i+1, s.Ref))
+	}
+	return strings.Join(refs, ", ")
+}
+
 // waitForInput blocks until user input or context cancellation.
 // Returns the trimmed input and whether the REPL should exit.
 func waitForInput(ctx context.Context, inputCh <-chan inputResult, scanner *bufio.Scanner) (string, bool) {
@@ -135,25 +210,38 @@ func buildChatOpts(baseOpts app.ChatOptions, ctx []app.Message) app.ChatOptions
 	opts := baseOpts
 	opts.Context = ctx
 	if len(ctx) > 0 {
-		opts.FilePath = ""
+		opts.Files = nil
 	}
 	return opts
 }
 
-func sendWithSpinner(ctx context.Context, client *app.Client, input string, opts app.ChatOptions) (string, error) {
+func sendWithSpinner(ctx context.Context, client *app.Client, input string, opts app.ChatOptions) (string, app.Usage, error) {
 	var spinnerStop atomic.Bool
 	go animateThinking(nil, &spinnerStop)
 
-	response, _, err := client.Chat(ctx, input, opts)
+	response, usage, err := client.Chat(ctx, input, opts)
 	spinnerStop.Store(true)
 	time.Sleep(100 * time.Millisecond) // Let spinner clear
 
-	return response, err
+	return response, usage, err
+}
+
+// userMessage builds the history entry for a user turn. Images are remote
+// URLs or data: URLs prepared when they were attached.
+func userMessage(input string, images []string) app.Message {
+	msg := app.Message{Role: "user", Content: input}
+	for _, img := range images {
+		msg.Parts = append(msg.Parts, app.ImagePart(img))
+	}
+	if len(msg.Parts) > 0 {
+		msg.Parts = append(msg.Parts, app.TextPart(input))
+	}
+	return msg
 }
 
-func appendExchange(ctx []app.Message, input, response string, maxMessages int) []app.Message {
+func appendExchange(ctx []app.Message, user app.Message, response string, maxMessages int) []app.Message {
 	ctx = append(ctx,
-		app.Message{Role: "user", Content: input},
+		user,
 		app.Message{Role: "assistant", Content: response},
 	)
 	if len(ctx) > maxMessages {
@@ -167,17 +255,27 @@ func printWelcomeBanner() {
 	fmt.Println(theme.Title.Render(" SYN ") + " " + theme.Description.Render("Chat Session"))
 	fmt.Println()
 	fmt.Println(theme.Info.Render("  Model: ") + theme.Dim.Render(viper.GetString("api.model")))
+	if chatIndex != "" {
+		fmt.Println(theme.Info.Render("  Index: ") + theme.Dim.Render(chatIndex))
+	}
 	fmt.Println()
-	fmt.Println(theme.HelpText.Render("  Commands: /help, /clear, /model, /exit"))
+	fmt.Println(theme.HelpText.Render("  Commands: /help, /clear, /model, /image, /sources, /exit"))
 	fmt.Println(theme.Divider.Render(strings.Repeat("-", 50)))
 	fmt.Println()
 }
 
 // handleChatCommand processes chat commands. Returns true if command was handled.
-func handleChatCommand(input string, context *[]app.Message) bool {
+func handleChatCommand(input string, session *chatSession, client *app.Client) bool {
+	if name, arg, _ := strings.Cut(input, " "); strings.EqualFold(name, "/image") {
+		attachImage(session, client, strings.TrimSpace(arg))
+		return true
+	}
+
 	switch strings.ToLower(input) {
 	case "/clear":
-		*context = nil
+		session.context = nil
+		session.sources = nil
+		session.images = nil
 		fmt.Print("\033[2J\033[H") // Clear screen
 		printWelcomeBanner()
 		return true
@@ -202,7 +300,17 @@ func handleChatCommand(input string, context *[]app.Message) bool {
 		return true
 
 	case "/context":
-		printContextStyled(*context)
+		printContextStyled(session.context)
+		return true
+
+	case "/sources":
+		fmt.Println()
+		if session.retriever == nil {
+			fmt.Println(theme.Dim.Render("  No index attached. Start with: syn chat --index <name>"))
+		} else {
+			printSources(os.Stdout, session.sources)
+		}
+		fmt.Println()
 		return true
 
 	default:
@@ -219,6 +327,48 @@ func handleChatCommand(input string, context *[]app.Message) bool {
 	}
 }
 
+// attachImage validates src, encodes local files once, and queues the image
+// for the next turn. With no argument it lists the queued images.
+func attachImage(session *chatSession, client *app.Client, src string) {
+	fmt.Println()
+	defer fmt.Println()
+
+	if src == "" {
+		if len(session.images) == 0 {
+			fmt.Println(theme.Dim.Render("  No images attached. Usage: /image <path|url>"))
+			return
+		}
+		for _, img := range session.images {
+			fmt.Printf("  %s %s\n", theme.Info.Render("Attached:"), theme.Dim.Render(img.name))
+		}
+		return
+	}
+
+	if !strings.HasPrefix(src, "http://") && !strings.HasPrefix(src, "https://") {
+		abs, err := filepath.Abs(src)
+		if err == nil {
+			src = abs
+		}
+		if info, err := os.Stat(src); err != nil || info.IsDir() {
+			fmt.Printf("  %s %s\n", theme.ErrorText.Render("Cannot attach:"), theme.Dim.Render(src))
+			return
+		}
+		if !app.IsImagePath(src) {
+			fmt.Printf("  %s %s\n", theme.ErrorText.Render("Not a supported image (jpg, png, gif, webp):"), theme.Dim.Render(src))
+			return
+		}
+	}
+
+	url, err := client.PrepareImage(src)
+	if err != nil {
+		fmt.Printf("  %s %s\n", theme.ErrorText.Render("Cannot attach:"), theme.Dim.Render(err.Error()))
+		return
+	}
+	session.images = append(session.images, attachment{name: src, url: url})
+	fmt.Printf("  %s %s\n", theme.Info.Render("Attached:"), theme.Dim.Render(src))
+	fmt.Println(theme.HelpText.Render("  It will be sent with your next message."))
+}
+
 func printChatHelp() {
 	fmt.Println()
 	fmt.Println(theme.Section.Render("Chat Commands"))
@@ -232,6 +382,8 @@ func printChatHelp() {
 		{"/clear", "Clear conversation and screen"},
 		{"/model", "Show current model"},
 		{"/context", "Show conversation context"},
+		{"/image <src>", "Attach an image to the next message"},
+		{"/sources", "Show sources used for the last answer"},
 		{"/exit", "Exit chat session"},
 	}
 
@@ -261,9 +413,13 @@ func printContextStyled(ctx []app.Message) {
 		} else {
 			styledRole = theme.AssistantPrompt.Render("[Syn]")
 		}
+		text := truncateString(msg.Content, 50)
+		if n := len(msg.Images()); n > 0 {
+			text += fmt.Sprintf(" [+%d image(s)]", n)
+		}
 		fmt.Printf("  %s %s\n",
 			styledRole,
-			theme.Dim.Render(truncateString(msg.Content, 50)))
+			theme.Dim.Render(text))
 	}
 	fmt.Println()
 }
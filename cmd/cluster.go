@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/dotcommander/syn/internal/app"
+	"github.com/dotcommander/syn/internal/vector"
+)
+
+var ( //nolint:gochecknoglobals // cobra flag bindings require package-level vars
+	clusterK         int
+	clusterMethod    string
+	clusterThreshold float64
+	clusterNoLabel   bool
+	clusterSeed      uint64
+)
+
+var clusterCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "cluster",
+	Short: "Group lines into semantic clusters",
+	Long: `Embed lines and group them with k-means or agglomerative clustering.
+Each cluster is labelled with a short chat-model summary of its members.
+
+Lines are read from -f <file>, or from stdin.
+
+Examples:
+  syn cluster -f bug-titles.txt -k 8
+  cat errors.log | syn cluster --method agglomerative --threshold 0.8
+  syn cluster -f feedback.txt --no-label --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if clusterMethod != "kmeans" && clusterMethod != "agglomerative" {
+			return fmt.Errorf("invalid --method %q (expected kmeans or agglomerative)", clusterMethod)
+		}
+		lines, err := readLineInput(inputFiles())
+		if err != nil {
+			return err
+		}
+		return runCluster(cmd.Context(), lines)
+	},
+}
+
+func init() { //nolint:gochecknoinits // cobra command registration
+	rootCmd.AddCommand(clusterCmd)
+	clusterCmd.Flags().IntVarP(&clusterK, "clusters", "k", 8, "number of clusters (agglomerative: 0 = stop at --threshold)")
+	clusterCmd.Flags().StringVar(&clusterMethod, "method", "kmeans", "clustering method: kmeans or agglomerative")
+	clusterCmd.Flags().Float64Var(&clusterThreshold, "threshold", 0.75, "agglomerative: stop merging below this cosine similarity (with -k 0)")
+	clusterCmd.Flags().BoolVar(&clusterNoLabel, "no-label", false, "skip chat-model cluster labels")
+	clusterCmd.Flags().Uint64Var(&clusterSeed, "seed", 1, "k-means random seed")
+}
+
+// cluster is one group of lines with its label.
+type cluster struct {
+	ID      int      `json:"id"`
+	Label   string   `json:"label,omitempty"`
+	Size    int      `json:"size"`
+	Members []string `json:"members"`
+}
+
+func runCluster(parent context.Context, lines []string) error {
+	client := newClient()
+	ctx, cancel := context.WithTimeout(parent, 15*time.Minute)
+	defer cancel()
+
+	vectors, err := embedLines(ctx, client, lines)
+	if err != nil {
+		return err
+	}
+
+	var assign []int
+	if clusterMethod == "agglomerative" {
+		assign = vector.Agglomerative(vectors, clusterK, clusterThreshold)
+	} else {
+		assign = vector.KMeans(vectors, clusterK, 100, clusterSeed)
+	}
+
+	clusters := groupClusters(lines, assign)
+	if !clusterNoLabel {
+		labelClusters(ctx, client, clusters)
+	}
+
+	if viper.GetBool("json") {
+		return printJSON(map[string]any{"method": clusterMethod, "lines": len(lines), "clusters": clusters})
+	}
+
+	fmt.Println()
+	fmt.Println(theme.Section.Render(fmt.Sprintf("Clusters (%d from %d lines)", len(clusters), len(lines))))
+	fmt.Println(theme.Divider.Render(strings.Repeat("-", 60)))
+	for _, c := range clusters {
+		fmt.Println()
+		title := fmt.Sprintf("Cluster %d", c.ID+1)
+		if c.Label != "" {
+			title += ": " + c.Label
+		}
+		fmt.Printf("  %s %s\n", theme.Info.Render(title), theme.Dim.Render(fmt.Sprintf("(%d)", c.Size)))
+		for _, m := range c.Members[:min(5, len(c.Members))] {
+			fmt.Printf("    %s\n", theme.Description.Render(truncateString(m, 100)))
+		}
+		if more := len(c.Members) - 5; more > 0 {
+			fmt.Printf("    %s\n", theme.Dim.Render(fmt.Sprintf("... %d more", more)))
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
+// groupClusters collects lines by cluster id, largest cluster first.
+func groupClusters(lines []string, assign []int) []cluster {
+	byID := map[int]*cluster{}
+	for i, id := range assign {
+		c, ok := byID[id]
+		if !ok {
+			c = &cluster{}
+			byID[id] = c
+		}
+		c.Members = append(c.Members, lines[i])
+	}
+
+	out := make([]cluster, 0, len(byID))
+	for _, c := range byID {
+		c.Size = len(c.Members)
+		out = append(out, *c)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Size > out[j].Size })
+	for i := range out {
+		out[i].ID = i
+	}
+	return out
+}
+
+// labelClusters asks the chat model for a short label per cluster.
+// Labelling failures leave the label empty rather than failing the command.
+func labelClusters(ctx context.Context, client app.ChatClient, clusters []cluster) {
+	for i := range clusters {
+		sample := clusters[i].Members[:min(15, len(clusters[i].Members))]
+		prompt := "Give a short label (2-6 words) describing what these items have in common. " +
+			"Reply with the label only.\n\n- " + strings.Join(sample, "\n- ")
+
+		opts := app.DefaultChatOptions()
+		opts.MaxTokens = app.IntPtr(32)
+		opts.Temperature = app.Float64Ptr(0.2)
+		if m := viper.GetString("model"); m != "" {
+			opts.Model = m
+		}
+
+		label, _, err := client.Chat(ctx, prompt, opts)
+		if err != nil {
+			if viper.GetBool("verbose") {
+				fmt.Fprintf(os.Stderr, "Cluster %d label failed: %v\n", i+1, err)
+			}
+			continue
+		}
+		clusters[i].Label = strings.Trim(strings.TrimSpace(label), `"'.`)
+	}
+}
@@ -0,0 +1,626 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+
+	"github.com/dotcommander/syn/internal/app"
+	"github.com/dotcommander/syn/internal/config"
+)
+
+var configCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "config",
+	Short: "Inspect, edit and validate configuration",
+	Long: `Inspect, edit and validate syn's configuration.
+
+show lists every effective setting and where its value came from (default,
+file, profile, env or flag). get and set read and write single keys in
+config.yaml using dotted names; set parses the value as YAML, so 5 is a
+number and true a boolean. init walks through first-run setup, and validate
+checks the file offline and its model names against the API.
+
+Profiles are named sets of settings under profiles: in config.yaml. The
+active profile's settings replace top-level ones; anything it leaves out is
+inherited. Select one with --profile, SYN_PROFILE or a top-level profile: key.
+
+  profiles:
+    work:
+      api:
+        key: syn_work_key
+        model: kimi
+    local-vllm:
+      api:
+        base_url: http://localhost:8000/v1
+        model: qwen
+
+Examples:
+  syn config init
+  syn config show
+  syn config get api.model
+  syn config set api.retry.max_attempts 5
+  syn config set profiles.work.api.model kimi
+  syn config validate
+  syn config profiles
+  syn --profile work "hello"
+  SYN_PROFILE=local-vllm syn chat`,
+}
+
+var configProfilesCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "profiles",
+	Short: "List configuration profiles",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigProfiles()
+	},
+}
+
+var configShowCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "show",
+	Short: "Show effective settings and their sources",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigShow()
+	},
+}
+
+var configGetCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "get <key>",
+	Short: "Print one effective setting",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigGet(args[0])
+	},
+}
+
+var configSetCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "set <key> <value>",
+	Short: "Write a setting to config.yaml",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigSet(args[0], args[1])
+	},
+}
+
+var configInitPlaintext bool //nolint:gochecknoglobals // cobra flag binding
+
+var configInitCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "init",
+	Short: "Set up the API key, base URL and default model",
+	Long: `Prompt for the API key, base URL and default model, check them against
+the API, and save them. A new key is stored in the keyring or encrypted file,
+as with syn auth login; --plaintext writes it to config.yaml instead.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigInit(cmd.Context())
+	},
+}
+
+var configValidateCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "validate",
+	Short: "Check settings and model names",
+	Long: `Check the effective configuration: value types, URLs, durations, ranges,
+unknown keys in config.yaml, and that configured models are served by the
+API. The model check is skipped with a warning when the API is unreachable.
+Exits with an error if any problem is an error rather than a warning.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigValidate(cmd.Context())
+	},
+}
+
+func init() { //nolint:gochecknoinits // cobra command registration
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configInitCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configProfilesCmd)
+
+	configInitCmd.Flags().BoolVar(&configInitPlaintext, "plaintext", false, "write the API key to config.yaml instead of the keyring or encrypted file")
+}
+
+// needsAPIKey reports whether cmd talks to the API; config and auth commands
+// must work before a key is configured.
+func needsAPIKey(cmd *cobra.Command) bool {
+	return !isSubcommand(cmd, configCmd) && !isSubcommand(cmd, authCmd)
+}
+
+// isSubcommand reports whether cmd is group or one of its subcommands.
+func isSubcommand(cmd, group *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == group {
+			return true
+		}
+	}
+	return false
+}
+
+// annotateKeySource reports a key read from the keyring or encrypted file
+// under that store's name rather than as a default, and a stored key that
+// could not be read as locked.
+func annotateKeySource(s *config.Setting) {
+	switch {
+	case s.Key != "api.key":
+	case storedKeySource != "":
+		s.Source = config.Source(storedKeySource)
+	case storedKeyErr != nil && s.Source == config.SourceDefault:
+		s.Source = sourceLocked
+	}
+}
+
+// activeProfile returns the profile selected by --profile, SYN_PROFILE or the
+// config file, lowercased as viper stores profile names.
+func activeProfile() string {
+	return strings.ToLower(viper.GetString("profile"))
+}
+
+type profileOutput struct {
+	Name    string `json:"name"`
+	Active  bool   `json:"active"`
+	BaseURL string `json:"base_url,omitempty"`
+	Model   string `json:"model,omitempty"`
+	HasKey  bool   `json:"has_key"`
+}
+
+func runConfigProfiles() error {
+	active := activeProfile()
+	names := config.ProfileNames(viper.GetViper())
+	out := make([]profileOutput, 0, len(names))
+	for _, name := range names {
+		prefix := "profiles." + name + ".api."
+		out = append(out, profileOutput{
+			Name:    name,
+			Active:  name == active,
+			BaseURL: viper.GetString(prefix + "base_url"),
+			Model:   viper.GetString(prefix + "model"),
+			HasKey:  viper.GetString(prefix+"key") != "",
+		})
+	}
+
+	if viper.GetBool("json") {
+		return printJSON(out)
+	}
+
+	fmt.Println()
+	fmt.Println(theme.Section.Render(fmt.Sprintf("Profiles (%d)", len(out))))
+	fmt.Println(theme.Divider.Render(strings.Repeat("-", 50)))
+	fmt.Println()
+	if len(out) == 0 {
+		fmt.Println(theme.Dim.Render("  No profiles defined. Add them under profiles: in config.yaml (see syn config --help)."))
+		fmt.Println()
+		return nil
+	}
+	for _, p := range out {
+		marker := "  "
+		if p.Active {
+			marker = theme.SuccessText.Render("* ")
+		}
+		var details []string
+		if p.BaseURL != "" {
+			details = append(details, p.BaseURL)
+		}
+		if p.Model != "" {
+			details = append(details, "model "+p.Model)
+		}
+		if p.HasKey {
+			details = append(details, "own key")
+		}
+		if len(details) == 0 {
+			details = append(details, "inherits api settings")
+		}
+		fmt.Printf("  %s%s  %s\n", marker,
+			theme.Command.Render(fmt.Sprintf("%-12s", p.Name)),
+			theme.Description.Render(strings.Join(details, ", ")))
+	}
+	fmt.Println()
+	if active == "" {
+		fmt.Println(theme.Dim.Render("  No profile active; select one with --profile or SYN_PROFILE."))
+		fmt.Println()
+	}
+	return nil
+}
+
+// configFileLabel returns the config file in use, or a note that none was found.
+func configFileLabel() string {
+	if used := viper.ConfigFileUsed(); used != "" {
+		return used
+	}
+	return "none (defaults and environment only)"
+}
+
+// formatValue renders a setting value on one line.
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []any, []string, map[string]any:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+	return fmt.Sprint(value)
+}
+
+func runConfigShow() error {
+	settings := config.Effective(viper.GetViper(), activeProfile(), flagSet)
+	for i := range settings {
+		settings[i].Value = config.Mask(settings[i].Key, settings[i].Value)
+		annotateKeySource(&settings[i])
+	}
+
+	if viper.GetBool("json") {
+		return printJSON(struct {
+			File     string           `json:"file"`
+			Profile  string           `json:"profile"`
+			Settings []config.Setting `json:"settings"`
+		}{viper.ConfigFileUsed(), activeProfile(), settings})
+	}
+
+	keyWidth, valueWidth := 0, 0
+	for _, s := range settings {
+		keyWidth = max(keyWidth, len(s.Key))
+		valueWidth = max(valueWidth, len(formatValue(s.Value)))
+	}
+	valueWidth = min(valueWidth, 48)
+
+	fmt.Println()
+	fmt.Println(theme.Section.Render("Configuration"))
+	fmt.Println(theme.Divider.Render(strings.Repeat("-", 50)))
+	fmt.Println()
+	fmt.Printf("  %s %s\n", theme.Dim.Render("File:   "), configFileLabel())
+	if profile := activeProfile(); profile != "" {
+		fmt.Printf("  %s %s\n", theme.Dim.Render("Profile:"), profile)
+	}
+	fmt.Println()
+	for _, s := range settings {
+		source := theme.Dim.Render(string(s.Source))
+		if s.Source != config.SourceDefault {
+			source = theme.Info.Render(string(s.Source))
+		}
+		fmt.Printf("  %s  %-*s  %s\n",
+			theme.Command.Render(fmt.Sprintf("%-*s", keyWidth, s.Key)),
+			valueWidth, formatValue(s.Value), source)
+	}
+	fmt.Println()
+	return nil
+}
+
+func runConfigGet(key string) error {
+	if !config.Known(key) {
+		return fmt.Errorf("unknown setting %q (see syn config show)", key)
+	}
+	s := config.Lookup(viper.GetViper(), key, activeProfile(), flagSet)
+	s.Value = config.Mask(s.Key, s.Value)
+	annotateKeySource(&s)
+	if viper.GetBool("json") {
+		return printJSON(s)
+	}
+	fmt.Println(formatValue(s.Value))
+	return nil
+}
+
+func runConfigSet(key, raw string) error {
+	key = strings.ToLower(key)
+	if !config.Known(key) {
+		return fmt.Errorf("unknown setting %q (see syn config show)", key)
+	}
+	value, err := config.ParseValue(raw)
+	if err != nil {
+		return err
+	}
+	for _, p := range config.CheckValue(key, value) {
+		if p.Level == config.LevelError {
+			return fmt.Errorf("%s: %s", key, p.Message)
+		}
+	}
+
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if err := config.SetValue(path, strings.Split(key, "."), value); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s %s = %s in %s\n", theme.SuccessText.Render("Set"), key,
+		formatValue(config.Mask(key, value)), path)
+	if s := config.Lookup(viper.GetViper(), key, activeProfile(), flagSet); s.Source == config.SourceEnv || s.Source == config.SourceFlag {
+		fmt.Fprintln(os.Stderr, theme.Dim.Render(fmt.Sprintf("(currently overridden by %s)", s.Source)))
+	}
+	return nil
+}
+
+// configPrompter reads first-run answers from stdin.
+type configPrompter struct {
+	reader *bufio.Reader
+}
+
+// ask prints a prompt with the current value and returns the answer, or
+// current when the answer is empty.
+func (p *configPrompter) ask(label, current string) (string, error) {
+	prompt := label + ":"
+	if current != "" {
+		prompt = fmt.Sprintf("%s [%s]:", label, current)
+	}
+	fmt.Printf("%s ", theme.UserPrompt.Render(prompt))
+	line, err := p.reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return current, nil
+}
+
+// askSecret is ask without echo when stdin is a terminal. The current value
+// is shown masked.
+func (p *configPrompter) askSecret(label, key, current string) (string, error) {
+	fd := int(os.Stdin.Fd()) //nolint:gosec // file descriptors fit in int
+	if !term.IsTerminal(fd) {
+		answer, err := p.ask(label, formatValue(config.Mask(key, current)))
+		if err != nil || answer == config.Mask(key, current) {
+			return current, err
+		}
+		return answer, nil
+	}
+
+	prompt := label + ":"
+	if current != "" {
+		prompt = fmt.Sprintf("%s [%s]:", label, config.Mask(key, current))
+	}
+	fmt.Printf("%s ", theme.UserPrompt.Render(prompt))
+	data, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	if answer := strings.TrimSpace(string(data)); answer != "" {
+		return answer, nil
+	}
+	return current, nil
+}
+
+func runConfigInit(parent context.Context) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Println(theme.Section.Render("Set up syn"))
+	fmt.Println(theme.Divider.Render(strings.Repeat("-", 50)))
+	fmt.Println(theme.Dim.Render("  Writing to " + path + ". Press Enter to keep the value in brackets."))
+	fmt.Println()
+
+	p := &configPrompter{reader: bufio.NewReader(os.Stdin)}
+	oldKey := viper.GetString("api.key")
+	key, err := p.askSecret("API key", "api.key", oldKey)
+	if err != nil {
+		return err
+	}
+	if key == "" {
+		return errors.New("an API key is required (get one at https://synthetic.new)")
+	}
+	baseURL, err := p.ask("API base URL", viper.GetString("api.base_url"))
+	if err != nil {
+		return err
+	}
+	aliases := slices.Sorted(maps.Keys(app.ModelAliases()))
+	fmt.Println(theme.Dim.Render("  Aliases: " + strings.Join(aliases, ", ")))
+	model, err := p.ask("Default model", viper.GetString("api.model"))
+	if err != nil {
+		return err
+	}
+
+	values := map[string]string{"api.key": key, "api.base_url": baseURL, "api.model": model}
+	for k, v := range values {
+		if problems := config.CheckValue(k, v); len(problems) > 0 && problems[0].Level == config.LevelError {
+			return fmt.Errorf("%s: %s", k, problems[0].Message)
+		}
+	}
+
+	viper.Set("api.key", key)
+	viper.Set("api.base_url", baseURL)
+	ctx, cancel := context.WithTimeout(parent, 30*time.Second)
+	defer cancel()
+	if served, err := servedModels(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "%s could not verify the key: %v\n", theme.ErrorText.Render("Warning:"), err)
+	} else if resolved := app.ResolveModel(model); !served[resolved] {
+		fmt.Fprintf(os.Stderr, "%s the API does not list model %s\n", theme.ErrorText.Render("Warning:"), resolved)
+	} else {
+		fmt.Println(theme.SuccessText.Render("  Key verified."))
+	}
+
+	fmt.Println()
+	if err := saveInitKey(path, key, oldKey); err != nil {
+		return err
+	}
+	for _, k := range []string{"api.base_url", "api.model"} {
+		if err := config.SetValue(path, strings.Split(k, "."), values[k]); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("%s %s\n", theme.SuccessText.Render("Saved"), path)
+	if os.Getenv("SYN_API_KEY") != "" || os.Getenv("SYNTHETIC_API_KEY") != "" {
+		fmt.Println(theme.Dim.Render("  Note: an API key in the environment overrides the one entered here."))
+	}
+	fmt.Println(theme.Dim.Render("  Run syn config validate to check the rest of the file."))
+	fmt.Println()
+	return nil
+}
+
+// saveInitKey saves the key entered in config init. An unchanged key stays
+// where it came from. A new one goes to the keyring or encrypted file, and a
+// key in config.yaml that would shadow it is removed; with --plaintext it is
+// written to config.yaml instead.
+func saveInitKey(path, key, oldKey string) error {
+	if key == oldKey {
+		return nil
+	}
+	if configInitPlaintext {
+		return config.SetValue(path, []string{"api", "key"}, key)
+	}
+
+	account := keyAccount()
+	where, err := storeKey(account, key, storeAuto)
+	if err != nil {
+		return fmt.Errorf("store API key: %w (or rerun with --plaintext)", err)
+	}
+	fmt.Printf("%s API key for %s in %s\n", theme.SuccessText.Render("Stored"), account, where)
+	removed, err := config.DeleteValue(path, []string{"api", "key"})
+	if err != nil {
+		return err
+	}
+	if removed {
+		fmt.Println(theme.Dim.Render("  Removed the old api.key from " + path + "."))
+	}
+	if configuredKey().Source == config.SourceProfile {
+		fmt.Println(theme.Dim.Render("  Note: the key in profile " + activeProfile() + " takes precedence; remove it to use the stored one."))
+	}
+	return nil
+}
+
+// modelRefs returns config keys naming models, with the model each names.
+// Keys in required must be served; the rest only warn.
+func modelRefs() (required, optional map[string]string) {
+	required = map[string]string{}
+	optional = map[string]string{}
+	for _, key := range []string{"api.model", "api.vision_model"} {
+		if m := viper.GetString(key); m != "" {
+			required[key] = app.ResolveModel(m)
+		}
+	}
+	if m := viper.GetString("api.embedding_model"); m != "" {
+		optional["api.embedding_model"] = app.ResolveModel(m)
+	}
+	for name, target := range viper.GetStringMapString("models.aliases") {
+		optional["models.aliases."+name] = target
+	}
+	fallbacks := viper.GetStringMap("models.fallbacks")
+	for name, model := range configModels("models.fallbacks", slices.Collect(maps.Keys(fallbacks)), nil) {
+		key := "models.fallbacks." + name
+		optional[key] = model
+		for i, m := range viper.GetStringSlice(key) {
+			optional[fmt.Sprintf("%s[%d]", key, i)] = app.ResolveModel(m)
+		}
+	}
+	limits := viper.GetStringMap("ratelimit.models")
+	for name, model := range configModels("ratelimit.models", slices.Collect(maps.Keys(limits)), nil) {
+		optional["ratelimit.models."+name] = model
+	}
+	return required, optional
+}
+
+// checkModels reports configured models the API does not serve.
+func checkModels(ctx context.Context) ([]config.Problem, error) {
+	served, err := servedModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	required, optional := modelRefs()
+	var problems []config.Problem
+	check := func(refs map[string]string, level string) {
+		for key, model := range refs {
+			if !served[model] {
+				problems = append(problems, config.Problem{Key: key, Level: level,
+					Message: fmt.Sprintf("model %s is not served by the API", model)})
+			}
+		}
+	}
+	check(required, config.LevelError)
+	check(optional, config.LevelWarning)
+	return problems, nil
+}
+
+func runConfigValidate(parent context.Context) error {
+	problems := config.Validate(viper.GetViper())
+	skipped := ""
+	if viper.GetString("api.key") == "" {
+		skipped = "no API key"
+		if note := lockedKeyNote(); note != "" {
+			skipped = note
+			for i, p := range problems {
+				if p.Key == "api.key" {
+					problems[i] = config.Problem{Key: p.Key, Level: config.LevelWarning, Message: note}
+				}
+			}
+		}
+	} else {
+		ctx, cancel := context.WithTimeout(parent, 30*time.Second)
+		defer cancel()
+		modelProblems, err := checkModels(ctx)
+		if err != nil {
+			skipped = err.Error()
+		}
+		problems = append(problems, modelProblems...)
+	}
+	slices.SortStableFunc(problems, func(a, b config.Problem) int { return strings.Compare(a.Key, b.Key) })
+
+	errCount := 0
+	for _, p := range problems {
+		if p.Level == config.LevelError {
+			errCount++
+		}
+	}
+
+	if viper.GetBool("json") {
+		out := struct {
+			File          string           `json:"file"`
+			Valid         bool             `json:"valid"`
+			Problems      []config.Problem `json:"problems"`
+			ModelsSkipped string           `json:"models_skipped,omitempty"`
+		}{viper.ConfigFileUsed(), errCount == 0, problems, skipped}
+		if out.Problems == nil {
+			out.Problems = []config.Problem{}
+		}
+		if err := printJSON(out); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println()
+		fmt.Printf("  %s %s\n", theme.Dim.Render("File:"), configFileLabel())
+		width := 0
+		for _, p := range problems {
+			width = max(width, len(p.Key))
+		}
+		fmt.Println()
+		for _, p := range problems {
+			level := theme.ErrorText.Render("error  ")
+			if p.Level == config.LevelWarning {
+				level = theme.Dim.Render("warning")
+			}
+			fmt.Printf("  %s %s  %s\n", level, theme.Command.Render(fmt.Sprintf("%-*s", width, p.Key)), p.Message)
+		}
+		if skipped != "" {
+			fmt.Fprintf(os.Stderr, "%s model names not checked: %s\n", theme.ErrorText.Render("Warning:"), skipped)
+		}
+		if len(problems) == 0 {
+			fmt.Println(theme.SuccessText.Render("  Configuration is valid."))
+		}
+		fmt.Println()
+	}
+
+	if errCount > 0 {
+		return fmt.Errorf("%d configuration error(s)", errCount)
+	}
+	return nil
+}
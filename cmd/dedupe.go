@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/dotcommander/syn/internal/vector"
+)
+
+var ( //nolint:gochecknoglobals // cobra flag bindings require package-level vars
+	dedupeThreshold  float64
+	dedupeShowGroups bool
+)
+
+var dedupeCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "dedupe",
+	Short: "Collapse near-duplicate lines",
+	Long: `Embed lines and collapse those whose cosine similarity to an earlier line is
+at or above --threshold. The first line of each group is kept, in input order.
+
+Lines are read from -f <file>, or from stdin. Kept lines are printed to stdout
+so the command can sit in a pipeline; the summary goes to stderr.
+
+Examples:
+  syn dedupe -f bug-reports.txt > unique.txt
+  cat app.log | syn dedupe --threshold 0.95
+  syn dedupe -f titles.txt --show-groups
+  syn dedupe -f titles.txt --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if dedupeThreshold <= 0 || dedupeThreshold > 1 {
+			return fmt.Errorf("invalid --threshold %.2f (expected 0 < t <= 1)", dedupeThreshold)
+		}
+		lines, err := readLineInput(inputFiles())
+		if err != nil {
+			return err
+		}
+		return runDedupe(cmd.Context(), lines)
+	},
+}
+
+func init() { //nolint:gochecknoinits // cobra command registration
+	rootCmd.AddCommand(dedupeCmd)
+	dedupeCmd.Flags().Float64Var(&dedupeThreshold, "threshold", 0.92, "cosine similarity at or above which lines are duplicates")
+	dedupeCmd.Flags().BoolVar(&dedupeShowGroups, "show-groups", false, "print each kept line with the duplicates it absorbed")
+}
+
+// dedupeGroup is a kept line and the near-duplicates collapsed into it.
+type dedupeGroup struct {
+	Kept       string   `json:"kept"`
+	Duplicates []string `json:"duplicates,omitempty"`
+}
+
+func runDedupe(parent context.Context, lines []string) error {
+	client := newClient()
+	ctx, cancel := context.WithTimeout(parent, 15*time.Minute)
+	defer cancel()
+
+	vectors, err := embedLines(ctx, client, lines)
+	if err != nil {
+		return err
+	}
+
+	assign := vector.DedupeGroups(vectors, dedupeThreshold)
+	groups := make([]dedupeGroup, 0, len(lines))
+	for i, g := range assign {
+		if g == len(groups) {
+			groups = append(groups, dedupeGroup{Kept: lines[i]})
+			continue
+		}
+		groups[g].Duplicates = append(groups[g].Duplicates, lines[i])
+	}
+
+	if viper.GetBool("json") {
+		return printJSON(map[string]any{
+			"threshold": dedupeThreshold,
+			"input":     len(lines),
+			"kept":      len(groups),
+			"groups":    groups,
+		})
+	}
+
+	for _, g := range groups {
+		fmt.Println(g.Kept)
+		if dedupeShowGroups {
+			for _, d := range g.Duplicates {
+				fmt.Println(theme.Dim.Render("  ~ " + d))
+			}
+		}
+	}
+	fmt.Fprintln(os.Stderr, theme.Dim.Render(fmt.Sprintf("Kept %d of %d lines (threshold %.2f)", len(groups), len(lines), dedupeThreshold)))
+	return nil
+}
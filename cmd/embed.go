@@ -1,14 +1,21 @@
 package cmd
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	"github.com/dotcommander/syn/internal/app"
+	"github.com/dotcommander/syn/internal/vector"
 )
 
 var embedCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
@@ -20,8 +27,35 @@ Examples:
   syn embed "Hello world"
   syn embed "Text 1" "Text 2" "Text 3"
   echo "Text" | syn embed
-  syn embed --json "Hello world"`,
+  syn embed --json "Hello world"
+  syn embed --file-per-line big.txt --json > vectors.json
+  syn embed --file-per-line big.txt --format csv --normalize > vectors.csv
+  syn embed --file-per-line big.txt --format npy --float32 --out vectors.npy
+  syn embed --dimensions 256 --normalize --format jsonl "Matryoshka"
+
+With --file-per-line, each non-empty line is one input. Long inputs are split
+into token-bounded chunks with overlap, embedded in concurrent batches with
+retry, and returned in input order.
+
+--format writes one id/text/vector row per input (or per chunk, with ids
+like "12.1" when an input was split):
+  jsonl  {"id","text","vector"} per line
+  csv    id,text,vector with pgvector-style "[x,y,...]" vectors
+  npy    2-D NumPy array of vectors only, in row order
+  bin    columnar binary: header, vector matrix, id and text columns`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateEmbedOutput(); err != nil {
+			return err
+		}
+
+		if embedFilePerLine != "" {
+			texts, err := readNonEmptyLines(embedFilePerLine)
+			if err != nil {
+				return err
+			}
+			return runEmbedBatch(cmd.Context(), texts)
+		}
+
 		var texts []string
 
 		// Check for stdin
@@ -44,8 +78,172 @@ Examples:
 	},
 }
 
+var ( //nolint:gochecknoglobals // cobra flag bindings require package-level vars
+	embedFilePerLine   string
+	embedBatchSize     int
+	embedConcurrency   int
+	embedMaxChunkToks  int
+	embedOverlapTokens int
+	embedFormat        string
+	embedOutPath       string
+	embedNormalize     bool
+	embedFloat32       bool
+	embedDimensions    int
+)
+
 func init() { //nolint:gochecknoinits // cobra command registration
 	rootCmd.AddCommand(embedCmd)
+
+	defaults := app.DefaultEmbedBatchOptions()
+	embedCmd.Flags().StringVar(&embedFilePerLine, "file-per-line", "", "embed each non-empty line of a file as a separate input")
+	embedCmd.Flags().IntVar(&embedBatchSize, "batch-size", defaults.BatchSize, "inputs per embedding request (with --file-per-line)")
+	embedCmd.Flags().IntVar(&embedConcurrency, "concurrency", defaults.Concurrency, "parallel embedding requests (with --file-per-line)")
+	embedCmd.Flags().IntVar(&embedMaxChunkToks, "max-tokens", defaults.MaxChunkTokens, "split inputs longer than this many tokens (0 = never split)")
+	embedCmd.Flags().IntVar(&embedOverlapTokens, "overlap", defaults.OverlapTokens, "tokens of overlap between split chunks")
+	embedCmd.Flags().StringVar(&embedFormat, "format", "", "row output format: "+strings.Join(vector.Formats, ", "))
+	embedCmd.Flags().StringVarP(&embedOutPath, "out", "o", "", "write --format output to a file instead of stdout")
+	embedCmd.Flags().BoolVar(&embedNormalize, "normalize", false, "L2-normalize vectors (after --dimensions truncation)")
+	embedCmd.Flags().BoolVar(&embedFloat32, "float32", false, "downcast --format output to float32")
+	embedCmd.Flags().IntVar(&embedDimensions, "dimensions", 0, "truncate vectors to the first N dimensions (Matryoshka models)")
+}
+
+// validateEmbedOutput checks output flags before any API call is made.
+func validateEmbedOutput() error {
+	if embedDimensions < 0 {
+		return fmt.Errorf("invalid --dimensions %d (must be >= 0)", embedDimensions)
+	}
+	if embedFormat == "" {
+		if embedOutPath != "" || embedFloat32 {
+			return fmt.Errorf("--out and --float32 require --format")
+		}
+		return nil
+	}
+	if !slices.Contains(vector.Formats, embedFormat) {
+		return fmt.Errorf("invalid --format %q (expected %s)", embedFormat, strings.Join(vector.Formats, ", "))
+	}
+	if vector.IsBinary(embedFormat) && embedOutPath == "" && isTerminal(os.Stdout) {
+		return fmt.Errorf("--format %s writes binary data: use --out <file> or redirect stdout", embedFormat)
+	}
+	return nil
+}
+
+// shapeVector applies --dimensions truncation and --normalize to v.
+func shapeVector(v []float64) []float64 {
+	v = vector.Truncate(v, embedDimensions)
+	if embedNormalize {
+		vector.Normalize(v)
+	}
+	return v
+}
+
+// writeEmbedRows writes rows in --format to --out or stdout.
+func writeEmbedRows(rows []vector.Row) error {
+	opts := vector.WriteOptions{Float32: embedFloat32}
+	if embedOutPath == "" {
+		return vector.Write(os.Stdout, embedFormat, rows, opts)
+	}
+
+	f, err := os.Create(embedOutPath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", embedOutPath, err)
+	}
+	if err := vector.Write(f, embedFormat, rows, opts); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", embedOutPath, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", embedOutPath, err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d vectors to %s\n", len(rows), embedOutPath)
+	return nil
+}
+
+// isTerminal reports whether f is attached to a terminal.
+func isTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+	return err == nil && (stat.Mode()&os.ModeCharDevice) != 0
+}
+
+// readNonEmptyLines returns the trimmed, non-empty lines of a file.
+func readNonEmptyLines(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	var lines []string
+	for line := range strings.SplitSeq(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("no non-empty lines in %s", path)
+	}
+	return lines, nil
+}
+
+func runEmbedBatch(parent context.Context, texts []string) error {
+	client := newClient()
+
+	ctx, cancel := context.WithTimeout(parent, 30*time.Minute)
+	defer cancel()
+
+	opts := app.EmbedBatchOptions{
+		Model:          viper.GetString("api.embedding_model"),
+		MaxChunkTokens: embedMaxChunkToks,
+		OverlapTokens:  embedOverlapTokens,
+		BatchSize:      embedBatchSize,
+		Concurrency:    embedConcurrency,
+	}
+
+	started := time.Now()
+	result, err := client.EmbedBatch(ctx, texts, opts)
+	if err != nil {
+		return fmt.Errorf("embedding failed: %w", err)
+	}
+
+	chunksPerInput := make(map[int]int, len(texts))
+	for i := range result.Chunks {
+		result.Chunks[i].Embedding = shapeVector(result.Chunks[i].Embedding)
+		chunksPerInput[result.Chunks[i].Input]++
+	}
+
+	if embedFormat != "" {
+		rows := make([]vector.Row, 0, len(result.Chunks))
+		for _, ch := range result.Chunks {
+			id := strconv.Itoa(ch.Input)
+			if chunksPerInput[ch.Input] > 1 {
+				id = fmt.Sprintf("%d.%d", ch.Input, ch.Chunk)
+			}
+			rows = append(rows, vector.Row{ID: id, Text: ch.Text, Vector: ch.Embedding})
+		}
+		return writeEmbedRows(rows)
+	}
+
+	if viper.GetBool("json") {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Println(theme.Section.Render(fmt.Sprintf("Embeddings (%d inputs, %d chunks)", len(texts), len(result.Chunks))))
+	fmt.Println(theme.Divider.Render(strings.Repeat("-", 50)))
+	fmt.Println()
+	fmt.Printf("  %s %s\n", theme.Info.Render("Model:"), theme.Description.Render(result.Model))
+	fmt.Printf("  %s %d\n", theme.Info.Render("Batches:"), result.Batches)
+	fmt.Printf("  %s %d\n", theme.Info.Render("Total tokens:"), result.Usage.TotalTokens)
+	if len(result.Chunks) > 0 {
+		fmt.Printf("  %s %d\n", theme.Info.Render("Dimensions:"), len(result.Chunks[0].Embedding))
+	}
+	fmt.Printf("  %s %.1fs\n", theme.Info.Render("Elapsed:"), time.Since(started).Seconds())
+	fmt.Println()
+	fmt.Println(theme.Dim.Render("  Use --json to write the vectors."))
+	fmt.Println()
+	return nil
 }
 
 func runEmbed(texts []string) error {
@@ -60,6 +258,24 @@ func runEmbed(texts []string) error {
 		return fmt.Errorf("embedding failed: %w", err)
 	}
 
+	// The API does not promise input order; Index ties each vector to its text.
+	slices.SortStableFunc(resp.Data, func(a, b app.EmbeddingData) int { return cmp.Compare(a.Index, b.Index) })
+	for i := range resp.Data {
+		resp.Data[i].Embedding = shapeVector(resp.Data[i].Embedding)
+	}
+
+	if embedFormat != "" {
+		rows := make([]vector.Row, 0, len(resp.Data))
+		for _, d := range resp.Data {
+			text := ""
+			if d.Index >= 0 && d.Index < len(texts) {
+				text = texts[d.Index]
+			}
+			rows = append(rows, vector.Row{ID: strconv.Itoa(d.Index), Text: text, Vector: d.Embedding})
+		}
+		return writeEmbedRows(rows)
+	}
+
 	if viper.GetBool("json") {
 		// Output raw JSON
 		data, err := json.MarshalIndent(resp, "", "  ")
@@ -82,10 +298,14 @@ func runEmbed(texts []string) error {
 			resp.Usage.TotalTokens)
 		fmt.Println()
 
-		for i, emb := range resp.Data {
+		for _, emb := range resp.Data {
+			text := ""
+			if emb.Index >= 0 && emb.Index < len(texts) {
+				text = texts[emb.Index]
+			}
 			fmt.Printf("  %s %s\n",
-				theme.Command.Render(fmt.Sprintf("Text %d:", i+1)),
-				theme.Dim.Render(truncateString(texts[i], 60)))
+				theme.Command.Render(fmt.Sprintf("Text %d:", emb.Index+1)),
+				theme.Dim.Render(truncateString(text, 60)))
 			fmt.Printf("    %s %d\n",
 				theme.Dim.Render("Dimensions:"),
 				len(emb.Embedding))
@@ -103,4 +323,3 @@ func runEmbed(texts []string) error {
 
 	return nil
 }
-
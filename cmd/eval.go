@@ -361,8 +361,10 @@ func evalModel(parent context.Context, client *app.Client, modelID string, cases
 	for _, c := range cases {
 		prompt := eval.BuildPrompt(c.Source)
 		opts := app.ChatOptions{
-			Model: modelID,
-			TopP:  app.Float64Ptr(1.0),
+			Model:      modelID,
+			TopP:       app.Float64Ptr(1.0),
+			NoFallback: true,
+			NoCache:    true, // cached answers would report no latency
 		}
 
 		ctx, cancel := context.WithTimeout(parent, 2*time.Minute)
@@ -370,6 +372,7 @@ func evalModel(parent context.Context, client *app.Client, modelID string, cases
 		cancel()
 
 		totalCompletionTokens += sr.Usage.CompletionTokens
+		res.WaitMS += sr.WaitMS
 
 		caseResult := eval.CaseResult{CaseID: c.ID, RawOutput: sr.Content, TTFMS: sr.TTFMS}
 		if chatErr != nil {
@@ -397,8 +400,8 @@ func evalModel(parent context.Context, client *app.Client, modelID string, cases
 	res.Summary = eval.ModelSummary{}
 	res.ElapsedMS = time.Since(started).Milliseconds()
 	res.CompletionTokens = totalCompletionTokens
-	if res.ElapsedMS > 0 {
-		res.TokensPerSec = float64(totalCompletionTokens) / (float64(res.ElapsedMS) / 1000)
+	if active := res.ElapsedMS - res.WaitMS; active > 0 {
+		res.TokensPerSec = float64(totalCompletionTokens) / (float64(active) / 1000)
 	}
 	if ttfCount > 0 {
 		res.AvgTTFMS = totalTTFMS / int64(ttfCount)
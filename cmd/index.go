@@ -0,0 +1,358 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/dotcommander/syn/internal/app"
+	"github.com/dotcommander/syn/internal/index"
+	"github.com/dotcommander/syn/internal/vector"
+)
+
+var ( //nolint:gochecknoglobals // cobra flag bindings require package-level vars
+	indexName      string
+	indexChunkSize int
+	indexOverlap   int
+	indexBatchSize int
+	indexTopK      int
+)
+
+var indexCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "index",
+	Short: "Local vector index for semantic search",
+	Long: `Build and query local vector indexes from your files.
+
+Files are chunked, embedded with the configured embedding model, and stored
+under ~/.config/syn/index/<name>.json (override with index.dir).
+
+Examples:
+  syn index add docs/ README.md
+  syn index add --name runbooks ~/runbooks
+  syn index query "how do I rotate the database password?"
+  syn index query --name runbooks -k 3 "pager escalation"
+  syn index list
+  syn index rm runbooks`,
+}
+
+var indexAddCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "add <paths...>",
+	Short: "Chunk, embed and store files in an index",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runIndexAdd(cmd.Context(), args)
+	},
+}
+
+var indexQueryCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "query <text>",
+	Short: "Return the chunks most similar to text",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runIndexQuery(cmd.Context(), strings.Join(args, " "))
+	},
+}
+
+var indexListCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "list",
+	Short: "List local indexes",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runIndexList()
+	},
+}
+
+var indexRmCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "rm <name>",
+	Short: "Delete a local index",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := indexDir()
+		if err != nil {
+			return err
+		}
+		if err := index.Remove(dir, args[0]); err != nil {
+			return err
+		}
+		fmt.Println(theme.SuccessText.Render(fmt.Sprintf("Removed index %s", args[0])))
+		return nil
+	},
+}
+
+func init() { //nolint:gochecknoinits // cobra command registration
+	rootCmd.AddCommand(indexCmd)
+	indexCmd.AddCommand(indexAddCmd, indexQueryCmd, indexListCmd, indexRmCmd)
+
+	indexCmd.PersistentFlags().StringVar(&indexName, "name", "default", "index name")
+	indexAddCmd.Flags().IntVar(&indexChunkSize, "chunk-size", 1500, "target chunk size in characters")
+	indexAddCmd.Flags().IntVar(&indexOverlap, "overlap", 200, "characters of overlap between consecutive chunks")
+	indexAddCmd.Flags().IntVar(&indexBatchSize, "batch-size", 32, "chunks per embedding request")
+	indexQueryCmd.Flags().IntVarP(&indexTopK, "top", "k", 5, "number of chunks to return")
+}
+
+// indexDir returns the configured index directory.
+func indexDir() (string, error) {
+	if dir := viper.GetString("index.dir"); dir != "" {
+		return dir, nil
+	}
+	return index.DefaultDir()
+}
+
+// loadOrCreateIndex loads the named index, creating it for model if missing.
+func loadOrCreateIndex(dir, name, model string) (*index.Index, error) {
+	path, err := index.Path(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	if _, statErr := os.Stat(path); os.IsNotExist(statErr) {
+		return index.New(name, model), nil
+	}
+	ix, err := index.Load(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	if ix.Model != model {
+		return nil, fmt.Errorf("index %q was built with %s but the embedding model is %s", name, ix.Model, model)
+	}
+	return ix, nil
+}
+
+func runIndexAdd(parent context.Context, paths []string) error {
+	dir, err := indexDir()
+	if err != nil {
+		return err
+	}
+	model := viper.GetString("api.embedding_model")
+	ix, err := loadOrCreateIndex(dir, indexName, model)
+	if err != nil {
+		return err
+	}
+
+	files, err := collectIndexFiles(paths)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no text files found in %s", strings.Join(paths, ", "))
+	}
+
+	client := newClient()
+	ctx, cancel := context.WithTimeout(parent, 30*time.Minute)
+	defer cancel()
+
+	embedOpts := app.DefaultEmbedBatchOptions()
+	embedOpts.Model = model
+	embedOpts.BatchSize = indexBatchSize
+
+	added := 0
+	for _, file := range files {
+		chunks, err := chunkFile(file)
+		if err != nil {
+			return err
+		}
+		if len(chunks) == 0 {
+			continue
+		}
+		if err := index.EmbedChunks(ctx, client, embedOpts, chunks); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if err := ix.Replace(file, chunks); err != nil {
+			return err
+		}
+		added += len(chunks)
+		if !viper.GetBool("json") {
+			fmt.Printf("  %s %s\n", theme.Command.Render(fmt.Sprintf("%4d", len(chunks))), theme.Dim.Render(file))
+		}
+	}
+
+	if err := ix.Save(dir); err != nil {
+		return err
+	}
+
+	path, _ := index.Path(dir, ix.Name)
+	summary := ix.Summary(path)
+	if viper.GetBool("json") {
+		return printJSON(map[string]any{"index": summary, "files": len(files), "chunks_added": added})
+	}
+
+	fmt.Println()
+	fmt.Println(theme.SuccessText.Render(fmt.Sprintf("Indexed %d chunks from %d files into %q (%d chunks total)",
+		added, len(files), ix.Name, summary.Chunks)))
+	return nil
+}
+
+// chunkFile reads a file and splits it into unembedded index chunks.
+func chunkFile(path string) ([]index.Chunk, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+
+	pieces := index.ChunkText(string(data), indexChunkSize, indexOverlap)
+	chunks := make([]index.Chunk, 0, len(pieces))
+	for i, p := range pieces {
+		chunks = append(chunks, index.Chunk{
+			ID:        fmt.Sprintf("%s#%d", path, i+1),
+			Source:    path,
+			StartLine: p.StartLine,
+			EndLine:   p.EndLine,
+			Text:      p.Text,
+		})
+	}
+	return chunks, nil
+}
+
+// collectIndexFiles expands paths into text files, walking directories and
+// skipping hidden entries and binary files.
+func collectIndexFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, root := range paths {
+		root = filepath.Clean(root)
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() || !d.Type().IsRegular() {
+				return nil
+			}
+			if isBinaryFile(path) {
+				return nil
+			}
+			files = append(files, path)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", root, err)
+		}
+	}
+	return files, nil
+}
+
+// isBinaryFile reports whether the first 8KB of a file contain a NUL byte.
+func isBinaryFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return true
+	}
+	defer f.Close()
+
+	buf := make([]byte, 8192)
+	n, _ := f.Read(buf)
+	return bytes.IndexByte(buf[:n], 0) >= 0
+}
+
+// queryIndex embeds text and returns the top k hits from ix.
+func queryIndex(ctx context.Context, client app.EmbeddingClient, ix *index.Index, text string, k int) ([]index.Hit, error) {
+	resp, err := client.Embed(ctx, []string{text}, ix.Model)
+	if err != nil {
+		return nil, fmt.Errorf("embedding failed: %w", err)
+	}
+	if len(resp.Data) == 0 {
+		return nil, fmt.Errorf("embedding failed: empty response")
+	}
+	return ix.Query(vector.FromFloat64(resp.Data[0].Embedding), k), nil
+}
+
+func runIndexQuery(parent context.Context, text string) error {
+	dir, err := indexDir()
+	if err != nil {
+		return err
+	}
+	ix, err := index.Load(dir, indexName)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(parent, 1*time.Minute)
+	defer cancel()
+
+	hits, err := queryIndex(ctx, newClient(), ix, text, indexTopK)
+	if err != nil {
+		return err
+	}
+
+	if viper.GetBool("json") {
+		type jsonHit struct {
+			Rank     int     `json:"rank"`
+			Score    float64 `json:"score"`
+			Source   string  `json:"source"`
+			Location string  `json:"location"`
+			Text     string  `json:"text"`
+		}
+		out := make([]jsonHit, 0, len(hits))
+		for i, h := range hits {
+			out = append(out, jsonHit{Rank: i + 1, Score: h.Score, Source: h.Chunk.Source, Location: h.Chunk.Location(), Text: h.Chunk.Text})
+		}
+		return printJSON(map[string]any{"index": ix.Name, "query": text, "results": out})
+	}
+
+	fmt.Println()
+	fmt.Println(theme.Section.Render(fmt.Sprintf("Top %d chunks in %q", len(hits), ix.Name)))
+	fmt.Println(theme.Divider.Render(strings.Repeat("-", 60)))
+	fmt.Println()
+	for i, h := range hits {
+		fmt.Printf("  %s %s  %s\n",
+			theme.Command.Render(fmt.Sprintf("%d.", i+1)),
+			theme.Info.Render(h.Chunk.Location()),
+			theme.Dim.Render(fmt.Sprintf("%.3f", h.Score)))
+		fmt.Printf("     %s\n\n", theme.Description.Render(truncateString(h.Chunk.Text, 160)))
+	}
+	return nil
+}
+
+func runIndexList() error {
+	dir, err := indexDir()
+	if err != nil {
+		return err
+	}
+	summaries, err := index.List(dir)
+	if err != nil {
+		return err
+	}
+
+	if viper.GetBool("json") {
+		return printJSON(summaries)
+	}
+
+	fmt.Println()
+	fmt.Println(theme.Section.Render(fmt.Sprintf("Indexes (%d)", len(summaries))))
+	fmt.Println(theme.Divider.Render(strings.Repeat("-", 50)))
+	fmt.Println()
+	if len(summaries) == 0 {
+		fmt.Println(theme.Dim.Render("  No indexes yet. Create one with: syn index add <paths>"))
+		fmt.Println()
+		return nil
+	}
+	for _, s := range summaries {
+		fmt.Printf("  %s  %s\n",
+			theme.Command.Render(fmt.Sprintf("%-16s", s.Name)),
+			theme.Dim.Render(fmt.Sprintf("%d chunks, %d files, %s, updated %s",
+				s.Chunks, s.Sources, s.Model, s.UpdatedAt.Format("2006-01-02 15:04"))))
+	}
+	fmt.Println()
+	return nil
+}
+
+// printJSON writes v as indented JSON to stdout.
+func printJSON(v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
@@ -3,13 +3,20 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"maps"
+	"os"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
 	"github.com/dotcommander/syn/internal/app"
+	"github.com/dotcommander/syn/internal/catalog"
+	"github.com/dotcommander/syn/internal/config"
 )
 
 var modelCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
@@ -33,6 +40,10 @@ var modelListCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command re
 
 		// Build reverse alias lookup: full model ID → []aliases
 		reverseAliases := buildReverseAliases()
+		cat := loadCatalog()
+		for _, m := range models {
+			cat.Merge(m.Capabilities(), catalog.SourceAPI)
+		}
 
 		// Sort models alphabetically by ID
 		sort.Slice(models, func(i, j int) bool {
@@ -59,7 +70,7 @@ var modelListCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command re
 			if len(aliases) > 0 {
 				tags = append(tags, theme.Flag.Render(strings.Join(aliases, ", ")))
 			}
-			if visionModels[m.ID] {
+			if cat.SupportsVision(m.ID) {
 				tags = append(tags, theme.Example.Render("vision"))
 			}
 
@@ -72,14 +83,411 @@ var modelListCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command re
 		}
 
 		fmt.Println()
+		warnStaleAliases(models)
 		return nil
 	},
 }
 
-// visionModels lists model IDs known to support image inputs.
-var visionModels = map[string]bool{ //nolint:gochecknoglobals // read-only lookup table
-	"hf:moonshotai/Kimi-K2.5":       true,
-	"hf:nvidia/Kimi-K2.5-NVFP4":     true,
+var modelInfoCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "info <id|alias>",
+	Short: "Show model capabilities",
+	Long: `Show context length, max output, modalities, tool support and pricing
+for a model, and check that the API currently serves it.
+
+Metadata from the /models API is merged over the built-in table; entries in
+~/.config/syn/models.yaml (models.catalog_file) override both.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runModelInfo(args[0])
+	},
+}
+
+// modelInfo is the JSON form of syn model info.
+type modelInfo struct {
+	catalog.Model
+	Aliases   []string `json:"aliases,omitempty"`
+	Available *bool    `json:"available"` // null when the check failed
+	CheckErr  string   `json:"availability_error,omitempty"`
+}
+
+func runModelInfo(name string) error {
+	id := app.ResolveModel(name)
+	cat := loadCatalog()
+
+	info := modelInfo{Aliases: buildReverseAliases()[id]}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	models, err := newClient().ListModels(ctx)
+	if err != nil {
+		info.CheckErr = err.Error()
+	} else {
+		available := false
+		for _, m := range models {
+			if m.ID == id {
+				cat.Merge(m.Capabilities(), catalog.SourceAPI)
+				available = true
+				break
+			}
+		}
+		info.Available = &available
+	}
+
+	m, known := cat.Lookup(id)
+	if !known && (info.Available == nil || !*info.Available) {
+		if info.CheckErr != "" {
+			return fmt.Errorf("unknown model %q (availability check failed: %s)", name, info.CheckErr)
+		}
+		return fmt.Errorf("unknown model %q: not served by the API and not in the catalog", name)
+	}
+	info.Model = m
+
+	if viper.GetBool("json") {
+		return printJSON(info)
+	}
+	printModelInfo(info)
+	return nil
+}
+
+func printModelInfo(info modelInfo) {
+	unknown := theme.Dim.Render("unknown")
+	orUnknown := func(s string) string {
+		if s == "" {
+			return unknown
+		}
+		return theme.Description.Render(s)
+	}
+	tokens := func(n int) string {
+		if n == 0 {
+			return unknown
+		}
+		return theme.Description.Render(fmt.Sprintf("%d tokens", n))
+	}
+
+	var availability string
+	switch {
+	case info.Available == nil:
+		availability = theme.Info.Render("unchecked") + " " + theme.Dim.Render(info.CheckErr)
+	case *info.Available:
+		availability = theme.SuccessText.Render("available")
+	default:
+		availability = theme.ErrorText.Render("not served by the API")
+	}
+
+	tools := unknown
+	if info.Tools != nil {
+		tools = theme.Description.Render(fmt.Sprintf("%t", *info.Tools))
+	}
+	pricing := unknown
+	if info.PromptPrice != "" || info.CompletionPrice != "" {
+		pricing = theme.Description.Render(fmt.Sprintf("input %s, output %s",
+			valueOr(info.PromptPrice, "?"), valueOr(info.CompletionPrice, "?")))
+	}
+
+	fmt.Println()
+	fmt.Println(theme.Section.Render(info.ID))
+	fmt.Println(theme.Divider.Render(strings.Repeat("-", 50)))
+	rows := [][2]string{
+		{"Name", orUnknown(info.Name)},
+		{"Aliases", orUnknown(strings.Join(info.Aliases, ", "))},
+		{"Status", availability},
+		{"Context", tokens(info.ContextLength)},
+		{"Max output", tokens(info.MaxOutput)},
+		{"Input", orUnknown(strings.Join(info.InputModalities, ", "))},
+		{"Output", orUnknown(strings.Join(info.OutputModalities, ", "))},
+		{"Tools", tools},
+		{"Pricing", pricing},
+		{"Sources", orUnknown(strings.Join(info.Sources, ", "))},
+	}
+	for _, r := range rows {
+		fmt.Printf("  %s %s\n", theme.Info.Render(fmt.Sprintf("%-11s", r[0])), r[1])
+	}
+	fmt.Println()
+}
+
+func valueOr(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+// loadCatalog reads the model capability catalog. An invalid user file is
+// reported and the built-in table used instead.
+func loadCatalog() *catalog.Catalog {
+	path := viper.GetString("models.catalog_file")
+	if path == "" {
+		path = catalog.DefaultPath()
+	}
+	cat, err := catalog.Load(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s\n", theme.ErrorText.Render("Warning:"), theme.Dim.Render(err.Error()))
+		return catalog.Builtin()
+	}
+	return cat
+}
+
+var modelAliasCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "alias",
+	Short: "Manage model aliases",
+	Long: `List, add and remove model aliases.
+
+User aliases live under models.aliases in config.yaml. They extend the
+built-in aliases and override them on conflict.`,
+}
+
+var modelAliasListCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "list",
+	Short: "List built-in and user aliases",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runModelAliasList()
+	},
+}
+
+var modelAliasAddCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "add <alias> <model-id>",
+	Short: "Add or override an alias in config.yaml",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runModelAliasAdd(args[0], args[1])
+	},
+}
+
+var modelAliasRmCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "rm <alias>",
+	Short: "Remove a user alias from config.yaml",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runModelAliasRm(args[0])
+	},
+}
+
+func runModelAliasList() error {
+	aliases := app.ModelAliases()
+	builtin := app.BuiltinModelAliases()
+	user := viper.GetStringMapString("models.aliases")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	served, checkErr := servedModels(ctx)
+
+	type jsonAlias struct {
+		Alias     string `json:"alias"`
+		Model     string `json:"model"`
+		Source    string `json:"source"` // builtin, user, or user (overrides builtin)
+		Available *bool  `json:"available"`
+	}
+	out := make([]jsonAlias, 0, len(aliases))
+	for _, name := range slices.Sorted(maps.Keys(aliases)) {
+		a := jsonAlias{Alias: name, Model: aliases[name], Source: "builtin"}
+		if _, ok := user[name]; ok {
+			a.Source = "user"
+			if _, ok := builtin[name]; ok {
+				a.Source = "user (overrides builtin)"
+			}
+		}
+		if checkErr == nil {
+			available := served[a.Model]
+			a.Available = &available
+		}
+		out = append(out, a)
+	}
+
+	if viper.GetBool("json") {
+		return printJSON(out)
+	}
+
+	fmt.Println()
+	fmt.Println(theme.Section.Render(fmt.Sprintf("Aliases (%d)", len(out))))
+	fmt.Println(theme.Divider.Render(strings.Repeat("-", 50)))
+	fmt.Println()
+	width := 0
+	for _, a := range out {
+		width = max(width, len(a.Model))
+	}
+	for _, a := range out {
+		status := ""
+		if a.Available != nil && !*a.Available {
+			status = "  " + theme.ErrorText.Render("not served")
+		}
+		fmt.Printf("  %s %s  %s%s\n",
+			theme.Command.Render(fmt.Sprintf("%-10s", a.Alias)),
+			theme.Description.Render(fmt.Sprintf("%-*s", width, a.Model)),
+			theme.Dim.Render(a.Source), status)
+	}
+	fmt.Println()
+	if checkErr != nil {
+		fmt.Println(theme.Dim.Render("  Availability not checked: " + checkErr.Error()))
+		fmt.Println()
+	}
+	return nil
+}
+
+func runModelAliasAdd(name, model string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return fmt.Errorf("invalid alias %q", name)
+	}
+	if _, ok := app.ModelAliases()[model]; ok {
+		return fmt.Errorf("%q is an alias; give the full model ID it should point to", model)
+	}
+
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if err := config.SetValue(path, []string{"models", "aliases", name}, model); err != nil {
+		return err
+	}
+
+	note := ""
+	if old, ok := app.BuiltinModelAliases()[name]; ok && old != model {
+		note = theme.Dim.Render(" (overrides builtin " + old + ")")
+	}
+	fmt.Printf("%s %s → %s%s\n", theme.SuccessText.Render("Added"), theme.Command.Render(name), model, note)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if served, err := servedModels(ctx); err == nil && !served[model] {
+		fmt.Fprintf(os.Stderr, "%s %s is not returned by the models API\n", theme.ErrorText.Render("Warning:"), model)
+	}
+	return nil
+}
+
+func runModelAliasRm(name string) error {
+	name = strings.ToLower(name)
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	removed, err := config.DeleteValue(path, []string{"models", "aliases", name})
+	if err != nil {
+		return err
+	}
+	if !removed {
+		if _, ok := app.BuiltinModelAliases()[name]; ok {
+			return fmt.Errorf("%q is a built-in alias; override it with syn model alias add", name)
+		}
+		return fmt.Errorf("no user alias %q in %s", name, path)
+	}
+
+	msg := theme.SuccessText.Render("Removed") + " " + theme.Command.Render(name)
+	if builtin, ok := app.BuiltinModelAliases()[name]; ok {
+		msg += theme.Dim.Render(" (built-in alias now points to " + builtin + ")")
+	}
+	fmt.Println(msg)
+	return nil
+}
+
+var modelPingCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "ping [model...]",
+	Short: "Probe model availability",
+	Long: `Send a one-token completion to each model, without retries or fallback,
+and report whether it answered and how long it took.
+
+With no arguments, pings the default model (-m or api.model) and its fallback
+chain from models.fallbacks. Exits with an error if any model is unavailable.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runModelPing(cmd.Context(), args)
+	},
+}
+
+// pingResult is the JSON form of one syn model ping probe.
+type pingResult struct {
+	Model     string `json:"model"`
+	OK        bool   `json:"ok"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+func runModelPing(parent context.Context, names []string) error {
+	client := newClient()
+	var models []string
+	if len(names) == 0 {
+		m := viper.GetString("model")
+		if m == "" {
+			m = viper.GetString("api.model")
+		}
+		models = client.FallbackChain(m)
+	}
+	for _, n := range names {
+		models = append(models, app.ResolveModel(n))
+	}
+
+	results := make([]pingResult, len(models))
+	var wg sync.WaitGroup
+	for i, m := range models {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(parent, 30*time.Second)
+			defer cancel()
+			latency, err := client.Ping(ctx, m)
+			results[i] = pingResult{Model: m, OK: err == nil, LatencyMS: latency.Milliseconds()}
+			if err != nil {
+				results[i].Error = err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+
+	down := 0
+	for _, r := range results {
+		if !r.OK {
+			down++
+		}
+	}
+
+	if viper.GetBool("json") {
+		if err := printJSON(results); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println()
+		for _, r := range results {
+			status := theme.SuccessText.Render("ok  ")
+			detail := theme.Dim.Render(fmt.Sprintf("%dms", r.LatencyMS))
+			if !r.OK {
+				status = theme.ErrorText.Render("down")
+				detail = theme.Dim.Render(r.Error)
+			}
+			fmt.Printf("  %s %s  %s\n", status, theme.Command.Render(r.Model), detail)
+		}
+		fmt.Println()
+	}
+
+	if down > 0 {
+		return fmt.Errorf("%d of %d models unavailable", down, len(results))
+	}
+	return nil
+}
+
+// servedModels returns the set of model IDs the API currently serves.
+func servedModels(ctx context.Context) (map[string]bool, error) {
+	models, err := newClient().ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	served := make(map[string]bool, len(models))
+	for _, m := range models {
+		served[m.ID] = true
+	}
+	return served, nil
+}
+
+// warnStaleAliases reports aliases whose target is missing from models.
+func warnStaleAliases(models []app.Model) {
+	served := make(map[string]bool, len(models))
+	for _, m := range models {
+		served[m.ID] = true
+	}
+	aliases := app.ModelAliases()
+	for _, name := range slices.Sorted(maps.Keys(aliases)) {
+		if !served[aliases[name]] {
+			fmt.Fprintf(os.Stderr, "%s alias %s points to %s, which the API no longer lists\n",
+				theme.ErrorText.Render("Warning:"), name, aliases[name])
+		}
+	}
 }
 
 // buildReverseAliases creates a map from full model ID to its short aliases.
@@ -98,4 +506,10 @@ func buildReverseAliases() map[string][]string {
 func init() { //nolint:gochecknoinits // cobra command registration
 	rootCmd.AddCommand(modelCmd)
 	modelCmd.AddCommand(modelListCmd)
+	modelCmd.AddCommand(modelInfoCmd)
+	modelCmd.AddCommand(modelPingCmd)
+	modelCmd.AddCommand(modelAliasCmd)
+	modelAliasCmd.AddCommand(modelAliasListCmd)
+	modelAliasCmd.AddCommand(modelAliasAddCmd)
+	modelAliasCmd.AddCommand(modelAliasRmCmd)
 }
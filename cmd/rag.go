@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/dotcommander/syn/internal/app"
+	"github.com/dotcommander/syn/internal/index"
+)
+
+// retriever looks up index chunks relevant to a user turn.
+type retriever struct {
+	ix     *index.Index
+	client app.EmbeddingClient
+	k      int
+}
+
+// newRetriever loads the named index for retrieval-augmented chat.
+func newRetriever(name string, client app.EmbeddingClient) (*retriever, error) {
+	dir, err := indexDir()
+	if err != nil {
+		return nil, err
+	}
+	ix, err := index.Load(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(ix.Chunks) == 0 {
+		return nil, fmt.Errorf("index %q is empty: add files with syn index add --name %s <paths>", name, name)
+	}
+	return &retriever{ix: ix, client: client, k: max(viper.GetInt("rag.top_k"), 1)}, nil
+}
+
+// Retrieve returns the top-k chunks for query as chat sources.
+func (r *retriever) Retrieve(ctx context.Context, query string) ([]app.Source, error) {
+	hits, err := queryIndex(ctx, r.client, r.ix, query, r.k)
+	if err != nil {
+		return nil, fmt.Errorf("retrieval from index %q failed: %w", r.ix.Name, err)
+	}
+
+	sources := make([]app.Source, 0, len(hits))
+	for _, h := range hits {
+		sources = append(sources, app.Source{Ref: h.Chunk.Location(), Text: h.Chunk.Text, Score: h.Score})
+	}
+	return sources, nil
+}
+
+// printSources writes a numbered list of retrieved sources matching the [n] citations.
+func printSources(w io.Writer, sources []app.Source) {
+	if len(sources) == 0 {
+		fmt.Fprintln(w, theme.Dim.Render("  No sources retrieved."))
+		return
+	}
+	fmt.Fprintln(w, theme.Section.Render(fmt.Sprintf("Sources (%d)", len(sources))))
+	fmt.Fprintln(w, theme.Divider.Render(strings.Repeat("-", 40)))
+	for i, s := range sources {
+		fmt.Fprintf(w, "  %s %s %s\n",
+			theme.Command.Render(fmt.Sprintf("[%d]", i+1)),
+			theme.Info.Render(s.Ref),
+			theme.Dim.Render(fmt.Sprintf("(%.3f)", s.Score)))
+	}
+}
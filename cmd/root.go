@@ -3,10 +3,13 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -14,14 +17,21 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/dotcommander/syn/internal/app"
+	"github.com/dotcommander/syn/internal/catalog"
+	"github.com/dotcommander/syn/internal/config"
+	"github.com/dotcommander/syn/internal/imgprep"
+	"github.com/dotcommander/syn/internal/ratelimit"
+	"github.com/dotcommander/syn/internal/textfile"
 )
 
 var ( //nolint:gochecknoglobals // cobra flag bindings require package-level vars
 	cfgFile    string
 	verbose    bool
-	filePath   string
+	filePaths  []string
 	jsonOutput bool
 	modelFlag  string
+	ragIndex   string
+	pagesFlag  string
 )
 
 var rootCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra root command
@@ -32,6 +42,10 @@ var rootCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra root command
 One-shot mode:
   syn "Explain quantum computing"
   syn -f main.go "Explain this code"
+  syn -f report.pdf --pages 1-5 "Summarize the findings"
+
+Retrieval-augmented (local index):
+  syn --rag runbooks "How do I fail over the database?"
 
 Piped input:
   pbpaste | syn "explain this"
@@ -44,7 +58,7 @@ Interactive REPL:
 		if cmd.Name() == "completion" || cmd.Name() == "help" || cmd.Name() == "version" {
 			return nil
 		}
-		return initConfig()
+		return initConfig(cmd)
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var prompt string
@@ -83,32 +97,117 @@ Interactive REPL:
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	shutdownTelemetry()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "\n%s %s\n\n",
 			theme.ErrorText.Render("Error:"),
 			theme.Description.Render(err.Error()))
+		if hint := errorHint(err); hint != "" {
+			fmt.Fprintf(os.Stderr, "%s\n\n", theme.Dim.Render(hint))
+		}
 		os.Exit(1)
 	}
 }
 
+// errorHint suggests a next step for API errors the user can act on.
+func errorHint(err error) string {
+	switch {
+	case errors.Is(err, app.ErrContextLength):
+		hint := "The prompt is too long for this model. Include fewer -f files or lower files.max_bytes"
+		if m, ok := largestContextModel(); ok {
+			hint += fmt.Sprintf(", or try %s (%d-token context)", m.ID, m.ContextLength)
+		}
+		return hint + "."
+	case errors.Is(err, app.ErrAuth):
+		return "Check the key from syn auth status, SYN_API_KEY or api.key in your config file."
+	case errors.Is(err, app.ErrRateLimited):
+		hint := "Rate limited by the provider"
+		var apiErr *app.APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			hint += "; retry after " + apiErr.RetryAfter.String()
+		}
+		return hint + ". Configure models.fallbacks to switch models automatically."
+	case errors.Is(err, app.ErrCircuitOpen):
+		return "Requests are failing fast after repeated errors. Wait for the cooldown (api.circuit_breaker.cooldown) or check with syn model ping."
+	case errors.Is(err, app.ErrUnavailable):
+		return "The model is unavailable. Check with syn model ping, or configure models.fallbacks."
+	case errors.Is(err, app.ErrModelNotFound):
+		return "Unknown model. See syn model list and syn model alias list."
+	}
+	return ""
+}
+
+// largestContextModel returns the catalog model with the largest context window.
+func largestContextModel() (catalog.Model, bool) {
+	var best catalog.Model
+	for _, m := range loadCatalog().Models() {
+		if m.ContextLength > best.ContextLength {
+			best = m
+		}
+	}
+	return best, best.ContextLength > 0
+}
+
 func init() { //nolint:gochecknoinits // cobra command registration
 	rootCmd.SilenceUsage = true
 	rootCmd.SilenceErrors = true
 	rootCmd.SetHelpFunc(styledHelp)
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default $HOME/.config/syn/config.yaml)")
+	rootCmd.PersistentFlags().String("profile", "", "config profile to use (default $SYN_PROFILE; see syn config profiles)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
-	rootCmd.PersistentFlags().StringVarP(&filePath, "file", "f", "", "include file contents in prompt")
+	rootCmd.PersistentFlags().StringArrayVarP(&filePaths, "file", "f", nil, "include file contents in prompt (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&pagesFlag, "pages", "", "PDF pages to include with -f, e.g. 1-5 or 2,4-6 (default: all)")
 	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "output in JSON format")
-	rootCmd.PersistentFlags().StringVarP(&modelFlag, "model", "m", "", "model to use (aliases: kimi, qwen, coder, glm, gpt, r1, minimax, llama)")
+	rootCmd.PersistentFlags().StringVarP(&modelFlag, "model", "m", "", modelFlagUsage())
+	rootCmd.PersistentFlags().Bool("no-cache", false, "neither read nor write the response and search caches")
+	rootCmd.PersistentFlags().Bool("refresh", false, "ignore cached responses and store fresh ones")
+	rootCmd.PersistentFlags().String("record", "", "record every HTTP exchange into cassette files in `dir`")
+	rootCmd.PersistentFlags().String("replay", "", "serve responses recorded in `dir` without network access")
+
+	rootCmd.Flags().StringVar(&ragIndex, "rag", "", "answer using chunks retrieved from a local index (see syn index)")
+
+	for key, name := range flagKeys {
+		_ = viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(name))
+	}
+}
+
+// flagKeys maps viper keys to the persistent flags bound to them.
+var flagKeys = map[string]string{ //nolint:gochecknoglobals // flag binding table
+	"profile":  "profile",
+	"verbose":  "verbose",
+	"file":     "file",
+	"json":     "json",
+	"no_cache": "no-cache",
+	"refresh":  "refresh",
+	"record":   "record",
+	"replay":   "replay",
+	"model":    "model",
+}
+
+// flagSet reports whether the flag bound to key was given.
+func flagSet(key string) bool {
+	name, ok := flagKeys[key]
+	if !ok {
+		return false
+	}
+	f := rootCmd.PersistentFlags().Lookup(name)
+	return f != nil && f.Changed
+}
 
-	_ = viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
-	_ = viper.BindPFlag("file", rootCmd.PersistentFlags().Lookup("file"))
-	_ = viper.BindPFlag("json", rootCmd.PersistentFlags().Lookup("json"))
-	_ = viper.BindPFlag("model", rootCmd.PersistentFlags().Lookup("model"))
+// modelFlagUsage lists the merged alias names for the --model help text.
+func modelFlagUsage() string {
+	names := slices.Sorted(maps.Keys(app.ModelAliases()))
+	return "model to use (aliases: " + strings.Join(names, ", ") + ")"
 }
 
 func styledHelp(cmd *cobra.Command, args []string) {
+	// Help skips PersistentPreRunE; read config so user aliases are listed.
+	if readConfig() == nil {
+		applyAliases(cmd.Root())
+	}
+
 	// For subcommands, print their description and usage
 	if cmd.Name() != "syn" {
 		fmt.Println()
@@ -137,6 +236,7 @@ func styledHelp(cmd *cobra.Command, args []string) {
 		`syn search "golang context"`,
 		`syn eval --limit 1`,
 		`syn embed "Hello world"`,
+		`syn index add docs/ && syn index query "deploy steps"`,
 	}
 	for _, ex := range examples {
 		fmt.Printf("  %s\n", theme.Example.Render(ex))
@@ -152,7 +252,13 @@ func styledHelp(cmd *cobra.Command, args []string) {
 		{"eval", "Evaluate key-insight extraction"},
 		{"vision", "Analyze images with AI"},
 		{"embed", "Generate text embeddings"},
+		{"index", "Local vector index (add, query)"},
+		{"similar", "Rank lines by semantic similarity"},
+		{"cluster", "Group lines into labelled clusters"},
+		{"dedupe", "Collapse near-duplicate lines"},
 		{"model", "Model management"},
+		{"config", "Show, edit and validate settings"},
+		{"auth", "Store the API key securely"},
 	}
 	for _, c := range commands {
 		fmt.Printf("  %s  %s\n",
@@ -165,9 +271,14 @@ func styledHelp(cmd *cobra.Command, args []string) {
 	fmt.Println(theme.Section.Render("Flags"))
 	fmt.Println(theme.Divider.Render(strings.Repeat("-", 50)))
 	flags := [][]string{
-		{"-m, --model <name>", "Model (kimi, qwen, coder, r1, glm, gpt, ...)"},
-		{"-f, --file <path>", "Include file contents in prompt"},
+		{"-m, --model <name>", "Model ID or alias (see syn model alias list)"},
+		{"--profile <name>", "Config profile (or SYN_PROFILE)"},
+		{"-f, --file <path>", "Include file contents in prompt (repeatable)"},
+		{"--pages <range>", "PDF pages to include (e.g. 1-5)"},
+		{"--rag <index>", "Ground answer in a local index"},
 		{"--json", "Output as JSON"},
+		{"--record <dir>", "Record HTTP exchanges to cassettes"},
+		{"--replay <dir>", "Replay cassettes without network"},
 		{"-v, --verbose", "Show debug info"},
 		{"-h, --help", "Show this help"},
 	}
@@ -182,7 +293,30 @@ func styledHelp(cmd *cobra.Command, args []string) {
 	fmt.Println()
 }
 
-func initConfig() error {
+func initConfig(cmd *cobra.Command) error {
+	if err := readConfig(); err != nil {
+		return err
+	}
+	applyAliases(cmd.Root())
+
+	if err := resolveStoredKey(cmd); err != nil {
+		return err
+	}
+	if err := setupCassette(); err != nil {
+		return err
+	}
+	if err := setupTelemetry(); err != nil {
+		return err
+	}
+	if viper.GetString("api.key") == "" && needsAPIKey(cmd) {
+		return fmt.Errorf("API key required: run syn auth login, set SYN_API_KEY or configure in ~/.config/syn/config.yaml")
+	}
+
+	return nil
+}
+
+// readConfig loads the config file and environment into viper.
+func readConfig() error {
 	if cfgFile != "" {
 		viper.SetConfigFile(cfgFile)
 	} else {
@@ -202,18 +336,91 @@ func initConfig() error {
 		}
 	}
 
-	viper.SetEnvPrefix("SYN")
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	viper.AutomaticEnv()
+	config.BindEnv(viper.GetViper())
+	return config.ApplyProfile(viper.GetViper(), activeProfile())
+}
 
-	// Also accept SYNTHETIC_API_KEY
-	_ = viper.BindEnv("api.key", "SYN_API_KEY", "SYNTHETIC_API_KEY")
+// applyAliases installs models.aliases from config and refreshes the
+// --model help text.
+func applyAliases(root *cobra.Command) {
+	app.SetUserAliases(viper.GetStringMapString("models.aliases"))
+	if f := root.PersistentFlags().Lookup("model"); f != nil {
+		f.Usage = modelFlagUsage()
+	}
+}
 
-	if viper.GetString("api.key") == "" {
-		return fmt.Errorf("API key required: set SYN_API_KEY or configure in ~/.config/syn/config.yaml")
+// configPath returns the config file that edits should be written to.
+func configPath() (string, error) {
+	if cfgFile != "" {
+		return cfgFile, nil
+	}
+	if used := viper.ConfigFileUsed(); used != "" {
+		return used, nil
 	}
+	return config.DefaultPath()
+}
 
-	return nil
+// configModels maps the keys of a config section keyed by model names, which
+// viper lowercases, to the models they name: aliases are resolved and full
+// model IDs get back the case written in the config file. With cat set,
+// keys naming no catalog model are warned about but kept.
+func configModels(section string, keys []string, cat *catalog.Catalog) map[string]string {
+	written, err := config.KeyCase(viper.ConfigFileUsed(), section, activeProfile())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s\n", theme.ErrorText.Render("Warning:"), theme.Dim.Render(err.Error()))
+	}
+	models := make(map[string]string, len(keys))
+	for _, key := range keys {
+		name := key
+		if w, ok := written[key]; ok {
+			name = w
+		}
+		model := app.ResolveModel(name)
+		if _, ok := cat.Lookup(model); cat != nil && !ok {
+			fmt.Fprintf(os.Stderr, "%s %s\n", theme.ErrorText.Render("Warning:"),
+				theme.Dim.Render(fmt.Sprintf("%s.%s matches no known model (see syn model list)", section, name)))
+		}
+		models[key] = model
+	}
+	return models
+}
+
+// buildFallbacks reads models.fallbacks, keyed by the models they apply to.
+func buildFallbacks(cat *catalog.Catalog) map[string][]string {
+	chains := viper.GetStringMapStringSlice("models.fallbacks")
+	names := configModels("models.fallbacks", slices.Collect(maps.Keys(chains)), cat)
+	fallbacks := make(map[string][]string, len(chains))
+	for name, chain := range chains {
+		fallbacks[names[name]] = chain
+	}
+	return fallbacks
+}
+
+// buildRateLimiter reads ratelimit.* into a limiter shared by all syn
+// processes, or nil when no limit is configured or responses are replayed.
+func buildRateLimiter(cat *catalog.Catalog) *ratelimit.Limiter {
+	if replaying() {
+		return nil
+	}
+	var perModel map[string]ratelimit.Limit
+	if err := viper.UnmarshalKey("ratelimit.models", &perModel); err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s\n", theme.ErrorText.Render("Warning:"), theme.Dim.Render("ignoring ratelimit.models: "+err.Error()))
+	}
+	names := configModels("ratelimit.models", slices.Collect(maps.Keys(perModel)), cat)
+	models := make(map[string]ratelimit.Limit, len(perModel))
+	for name, lim := range perModel {
+		models[names[name]] = lim
+	}
+
+	path := viper.GetString("ratelimit.state_file")
+	if path == "" {
+		path = ratelimit.DefaultPath()
+	}
+	defaults := ratelimit.Limit{
+		RequestsPerMinute: viper.GetInt("ratelimit.rpm"),
+		TokensPerMinute:   viper.GetInt("ratelimit.tpm"),
+	}
+	return ratelimit.New(path, defaults, models)
 }
 
 func buildClientConfig() app.ClientConfig {
@@ -223,21 +430,50 @@ func buildClientConfig() app.ClientConfig {
 		MaxBackoff:     viper.GetDuration("api.retry.max_backoff"),
 	}
 
+	cat := loadCatalog()
 	return app.ClientConfig{
 		APIKey:         viper.GetString("api.key"),
 		BaseURL:        viper.GetString("api.base_url"),
 		AnthropicURL:   viper.GetString("api.anthropic_base_url"),
 		Model:          viper.GetString("api.model"),
 		EmbeddingModel: viper.GetString("api.embedding_model"),
+		VisionModel:    viper.GetString("api.vision_model"),
+		Catalog:        cat,
+		Fallbacks:      buildFallbacks(cat),
+		RateLimiter:    buildRateLimiter(cat),
+		Cache:          newResponseCache(),
+		SearchCache:    newSearchCache(),
+		CacheMode:      cacheMode(),
+		Profile:        activeProfile(),
 		Verbose:        viper.GetBool("verbose"),
 		RetryConfig:    retryCfg,
+		CircuitBreaker: app.CircuitBreakerConfig{
+			Threshold: viper.GetInt("api.circuit_breaker.threshold"),
+			Cooldown:  viper.GetDuration("api.circuit_breaker.cooldown"),
+		},
+		Image: app.ImageConfig{
+			Options: imgprep.Options{
+				MaxDimension: viper.GetInt("vision.max_dimension"),
+				Quality:      viper.GetInt("vision.quality"),
+				Format:       viper.GetString("vision.format"),
+			},
+			MaxBytes: viper.GetInt("vision.max_bytes"),
+		},
+		Files: app.FileConfig{
+			Options: textfile.Options{
+				MaxBytes: viper.GetInt64("files.max_bytes"),
+				Binary:   viper.GetString("files.binary"),
+			},
+			WarnTokens: viper.GetInt("files.warn_tokens"),
+		},
 	}
 }
 
 func newClient() *app.Client {
 	cfg := buildClientConfig()
+	applyTelemetry(&cfg)
 	logger := app.NewLogger(cfg.Verbose)
-	return app.NewClient(cfg, logger, nil)
+	return app.NewClient(cfg, logger, httpDoer)
 }
 
 func hasStdinData() bool {
@@ -253,18 +489,37 @@ func readStdin() (string, error) {
 	return strings.TrimSpace(string(data)), nil
 }
 
+// printUsageNotes tells the user on stderr when the response came from the
+// cache or a fallback model.
+func printUsageNotes(usage app.Usage) {
+	if usage.Cached {
+		fmt.Fprintln(os.Stderr, theme.Dim.Render("(cached response; --refresh fetches a new one)"))
+	}
+	if usage.FallbackFrom == "" {
+		return
+	}
+	fmt.Fprintln(os.Stderr, theme.Dim.Render(fmt.Sprintf("(answered by %s; %s was unavailable)", usage.Model, usage.FallbackFrom)))
+}
+
+// inputFiles returns the paths given with -f, in order. It reads the flag
+// rather than viper, which would split each value on commas.
+func inputFiles() []string {
+	return filePaths
+}
+
 func runOneShot(prompt string) error {
 	client := newClient()
 	opts := app.DefaultChatOptions()
-	opts.FilePath = viper.GetString("file")
+	opts.Files = inputFiles()
+	opts.Pages = pagesFlag
 	if m := viper.GetString("model"); m != "" {
 		opts.Model = m
 	}
 
 	if viper.GetBool("verbose") {
 		fmt.Fprintf(os.Stderr, "Prompt: %s\n", prompt)
-		if opts.FilePath != "" {
-			fmt.Fprintf(os.Stderr, "File: %s\n", opts.FilePath)
+		for _, f := range opts.Files {
+			fmt.Fprintf(os.Stderr, "File: %s\n", f)
 		}
 		if opts.Model != "" {
 			fmt.Fprintf(os.Stderr, "Model: %s\n", app.ResolveModel(opts.Model))
@@ -274,19 +529,44 @@ func runOneShot(prompt string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
-	response, _, err := client.Chat(ctx, prompt, opts)
+	if ragIndex != "" {
+		r, err := newRetriever(ragIndex, client)
+		if err != nil {
+			return err
+		}
+		if opts.Sources, err = r.Retrieve(ctx, prompt); err != nil {
+			return err
+		}
+	}
+
+	response, usage, err := client.Chat(ctx, prompt, opts)
 	if err != nil {
 		return fmt.Errorf("failed to get response: %w", err)
 	}
 
 	if viper.GetBool("json") {
+		firstFile := ""
+		if len(opts.Files) > 0 {
+			firstFile = opts.Files[0]
+		}
 		output := map[string]any{
 			"prompt":    prompt,
 			"response":  response,
-			"model":     viper.GetString("api.model"),
-			"file":      opts.FilePath,
+			"model":     usage.Model,
+			"file":      firstFile, // kept for scripts written before -f was repeatable
+			"files":     opts.Files,
 			"timestamp": time.Now().Format(time.RFC3339),
 		}
+		if usage.FallbackFrom != "" {
+			output["fallback_from"] = usage.FallbackFrom
+		}
+		if usage.Cached {
+			output["cached"] = true
+		}
+		if ragIndex != "" {
+			output["index"] = ragIndex
+			output["sources"] = opts.Sources
+		}
 
 		data, err := json.MarshalIndent(output, "", "  ")
 		if err != nil {
@@ -295,6 +575,12 @@ func runOneShot(prompt string) error {
 		fmt.Println(string(data))
 	} else {
 		fmt.Println(response)
+		printUsageNotes(usage)
+		if ragIndex != "" {
+			// Sources go to stderr so redirected answers stay clean.
+			fmt.Fprintln(os.Stderr)
+			printSources(os.Stderr, opts.Sources)
+		}
 	}
 
 	return nil
@@ -13,6 +13,8 @@ import (
 	"time"
 
 	"github.com/dotcommander/syn/internal/app"
+	"github.com/dotcommander/syn/internal/cache"
+	"github.com/dotcommander/syn/internal/search"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -29,7 +31,12 @@ Examples:
   syn search "golang error handling"
   syn search --json "react hooks"
   syn search -i "claude docs"    # Interactive mode
-  echo "python async" | syn search`,
+  syn search --site go.dev --since 30d --limit 5 "generics"
+  syn search --exclude reddit --refresh "vim plugins"
+  echo "python async" | syn search
+
+Results are cached on disk per query (search.cache_ttl, default 1h; see
+syn cache), de-duplicated by canonical URL, then filtered client-side.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var query string
 
@@ -51,17 +58,50 @@ Examples:
 			return fmt.Errorf("no search query provided (use args or stdin)")
 		}
 
-		interactive, _ := cmd.Flags().GetBool("interactive")
-		return runSearch(query, interactive)
+		opts, err := searchOptionsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		return runSearch(query, opts)
 	},
 }
 
 func init() { //nolint:gochecknoinits // cobra command registration
 	rootCmd.AddCommand(searchCmd)
 	searchCmd.Flags().BoolP("interactive", "i", false, "Enable interactive result selection")
+	searchCmd.Flags().StringSlice("site", nil, "only keep results from these domains (repeatable)")
+	searchCmd.Flags().StringSlice("exclude", nil, "drop results whose title, URL or snippet contains a term (repeatable)")
+	searchCmd.Flags().String("since", "", "only keep results published since (e.g. 7d, 2w, 36h, 2025-01-31)")
+	searchCmd.Flags().Int("limit", 0, "maximum results to show (0 = all)")
+}
+
+// searchOptions holds the parsed search command flags.
+type searchOptions struct {
+	interactive bool
+	filter      search.Filter
+}
+
+func searchOptionsFromFlags(cmd *cobra.Command) (searchOptions, error) {
+	var opts searchOptions
+	opts.interactive, _ = cmd.Flags().GetBool("interactive")
+	opts.filter.Sites, _ = cmd.Flags().GetStringSlice("site")
+	opts.filter.Exclude, _ = cmd.Flags().GetStringSlice("exclude")
+	opts.filter.Limit, _ = cmd.Flags().GetInt("limit")
+
+	since, _ := cmd.Flags().GetString("since")
+	sinceTime, err := search.ParseSince(since, time.Now())
+	if err != nil {
+		return searchOptions{}, err
+	}
+	opts.filter.Since = sinceTime
+
+	if opts.filter.Limit < 0 {
+		return searchOptions{}, fmt.Errorf("invalid --limit %d (must be >= 0)", opts.filter.Limit)
+	}
+	return opts, nil
 }
 
-func runSearch(query string, interactive bool) error {
+func runSearch(query string, opts searchOptions) error {
 	client := newClient()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -72,31 +112,58 @@ func runSearch(query string, interactive bool) error {
 		return fmt.Errorf("search failed: %w", err)
 	}
 
+	info := resp.Cache
+	total := len(resp.Results)
+	resp = &app.SearchResponse{Results: search.Apply(resp.Results, opts.filter)}
+
 	// JSON mode - skip interactive
 	if viper.GetBool("json") {
-		data, err := json.MarshalIndent(resp, "", "  ")
-		if err != nil {
-			return err
-		}
-		fmt.Println(string(data))
-		return nil
+		return printSearchJSON(query, resp, info, total)
 	}
 
 	// Print results
-	printSearchResults(resp)
+	printSearchResults(resp, info)
 
 	// Interactive mode
-	if interactive && len(resp.Results) > 0 {
+	if opts.interactive && len(resp.Results) > 0 {
 		return interactiveSelection(resp.Results)
 	}
 
 	return nil
 }
 
-func printSearchResults(resp *app.SearchResponse) {
+func printSearchJSON(query string, resp *app.SearchResponse, info cache.Info, total int) error {
+	cacheMeta := map[string]any{
+		"hit":         info.Hit,
+		"ttl_seconds": int64(info.TTL.Seconds()),
+	}
+	if info.Hit {
+		cacheMeta["stored_at"] = info.StoredAt.Format(time.RFC3339)
+		cacheMeta["age_seconds"] = int64(info.Age.Seconds())
+	}
+
+	output := map[string]any{
+		"query":         query,
+		"results":       resp.Results,
+		"total_results": total,
+		"cache":         cacheMeta,
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+func printSearchResults(resp *app.SearchResponse, info cache.Info) {
 	fmt.Println()
 	fmt.Println(theme.Section.Render(fmt.Sprintf("Search Results (%d)", len(resp.Results))))
 	fmt.Println(theme.Divider.Render(strings.Repeat("-", 60)))
+	if info.Hit {
+		fmt.Println(theme.Dim.Render(fmt.Sprintf("  cached %s ago", info.Age.Round(time.Second))))
+	}
 	fmt.Println()
 
 	if len(resp.Results) == 0 {
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/dotcommander/syn/internal/app"
+	"github.com/dotcommander/syn/internal/vector"
+)
+
+var ( //nolint:gochecknoglobals // cobra flag bindings require package-level vars
+	similarTop       int
+	similarThreshold float64
+)
+
+var similarCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
+	Use:   "similar <query>",
+	Short: "Rank candidate lines by semantic similarity",
+	Long: `Embed a query and a list of candidates, then rank candidates by cosine similarity.
+
+Candidates are read one per line from -f <file>, or from stdin.
+
+Examples:
+  syn similar -f issues.txt "login page times out"
+  cat errors.log | syn similar -k 5 "disk quota exceeded"
+  syn similar -f faq.txt --threshold 0.7 --json "reset password"`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		candidates, err := readLineInput(inputFiles())
+		if err != nil {
+			return err
+		}
+		return runSimilar(cmd.Context(), strings.Join(args, " "), candidates)
+	},
+}
+
+func init() { //nolint:gochecknoinits // cobra command registration
+	rootCmd.AddCommand(similarCmd)
+	similarCmd.Flags().IntVarP(&similarTop, "top", "k", 10, "number of candidates to show (0 = all)")
+	similarCmd.Flags().Float64Var(&similarThreshold, "threshold", 0, "minimum cosine similarity to show")
+}
+
+// readLineInput reads non-empty lines from paths in order, or from stdin when
+// no paths are given.
+func readLineInput(paths []string) ([]string, error) {
+	if len(paths) > 0 {
+		var lines []string
+		for _, path := range paths {
+			more, err := readNonEmptyLines(path)
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, more...)
+		}
+		return lines, nil
+	}
+	if !hasStdinData() {
+		return nil, fmt.Errorf("no input: use -f <file> or pipe lines on stdin")
+	}
+	data, err := readStdin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stdin: %w", err)
+	}
+	var lines []string
+	for line := range strings.SplitSeq(data, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("no non-empty lines on stdin")
+	}
+	return lines, nil
+}
+
+// embedLines embeds each text through the batch pipeline and returns one unit
+// vector per text. Texts split into several chunks are mean-pooled.
+func embedLines(ctx context.Context, client app.BatchEmbeddingClient, texts []string) ([][]float32, error) {
+	opts := app.DefaultEmbedBatchOptions()
+	opts.Model = viper.GetString("api.embedding_model")
+
+	result, err := client.EmbedBatch(ctx, texts, opts)
+	if err != nil {
+		return nil, fmt.Errorf("embedding failed: %w", err)
+	}
+
+	chunks := make([][][]float32, len(texts))
+	for _, ch := range result.Chunks {
+		chunks[ch.Input] = append(chunks[ch.Input], vector.FromFloat64(ch.Embedding))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for i, c := range chunks {
+		vectors[i] = vector.Normalize(vector.Mean(c))
+	}
+	return vectors, nil
+}
+
+// similarMatch is one ranked candidate.
+type similarMatch struct {
+	Rank  int     `json:"rank"`
+	Line  int     `json:"line"`
+	Score float64 `json:"score"`
+	Text  string  `json:"text"`
+}
+
+func runSimilar(parent context.Context, query string, candidates []string) error {
+	client := newClient()
+	ctx, cancel := context.WithTimeout(parent, 10*time.Minute)
+	defer cancel()
+
+	vectors, err := embedLines(ctx, client, append([]string{query}, candidates...))
+	if err != nil {
+		return err
+	}
+
+	matches := make([]similarMatch, 0, len(candidates))
+	for i, text := range candidates {
+		score := vector.Dot(vectors[0], vectors[i+1])
+		if score < similarThreshold {
+			continue
+		}
+		matches = append(matches, similarMatch{Line: i + 1, Score: score, Text: text})
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if similarTop > 0 && len(matches) > similarTop {
+		matches = matches[:similarTop]
+	}
+	for i := range matches {
+		matches[i].Rank = i + 1
+	}
+
+	if viper.GetBool("json") {
+		return printJSON(map[string]any{"query": query, "candidates": len(candidates), "results": matches})
+	}
+
+	fmt.Println()
+	fmt.Println(theme.Section.Render(fmt.Sprintf("Most similar (%d of %d)", len(matches), len(candidates))))
+	fmt.Println(theme.Divider.Render(strings.Repeat("-", 60)))
+	fmt.Println()
+	for _, m := range matches {
+		fmt.Printf("  %s %s  %s\n",
+			theme.Command.Render(fmt.Sprintf("%3d.", m.Rank)),
+			theme.Info.Render(fmt.Sprintf("%.3f", m.Score)),
+			theme.Description.Render(truncateString(m.Text, 100)))
+	}
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+
+	"github.com/dotcommander/syn/internal/app"
+	"github.com/dotcommander/syn/internal/telemetry"
+)
+
+// telemetryProviders exports client spans and metrics when telemetry.enabled
+// is set; nil otherwise.
+var telemetryProviders *telemetry.Providers //nolint:gochecknoglobals // set once per run in initConfig
+
+// setupTelemetry builds the exporters configured under telemetry.*.
+func setupTelemetry() error {
+	if !viper.GetBool("telemetry.enabled") {
+		return nil
+	}
+	p, err := telemetry.Setup(context.Background(), telemetry.Config{
+		Exporter:       viper.GetString("telemetry.exporter"),
+		Endpoint:       viper.GetString("telemetry.endpoint"),
+		Headers:        viper.GetStringMapString("telemetry.headers"),
+		File:           viper.GetString("telemetry.file"),
+		ServiceName:    viper.GetString("telemetry.service_name"),
+		MetricInterval: viper.GetDuration("telemetry.metric_interval"),
+	})
+	if err != nil {
+		return err
+	}
+	telemetryProviders = p
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(warnTelemetry))
+	return nil
+}
+
+// applyTelemetry points cfg at the configured providers, leaving the otel
+// globals in place when telemetry is disabled.
+func applyTelemetry(cfg *app.ClientConfig) {
+	if telemetryProviders == nil {
+		return
+	}
+	cfg.TracerProvider = telemetryProviders.Tracer
+	cfg.MeterProvider = telemetryProviders.Meter
+}
+
+// shutdownTelemetry flushes buffered spans and metrics before exit. Export
+// failures are reported but never fail the command.
+func shutdownTelemetry() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := telemetryProviders.Shutdown(ctx); err != nil {
+		warnTelemetry(err)
+	}
+}
+
+func warnTelemetry(err error) {
+	fmt.Fprintf(os.Stderr, "%s %s\n", theme.ErrorText.Render("Warning:"), theme.Dim.Render("telemetry export failed: "+err.Error()))
+}
@@ -3,7 +3,10 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -11,6 +14,8 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/dotcommander/syn/internal/app"
+	"github.com/dotcommander/syn/internal/clipboard"
+	"github.com/dotcommander/syn/internal/document"
 )
 
 var visionCmd = &cobra.Command{ //nolint:gochecknoglobals // cobra command registration
@@ -22,50 +27,202 @@ Examples:
   syn vision -f photo.jpg "What's in this image?"
   syn vision -f https://example.com/image.png "Describe this"
   syn vision -f screenshot.png  # Uses default prompt
+  syn vision -f before.png -f after.png "What changed between these screenshots?"
+  syn vision -f 'shots/*.png' -f spec.md "Do these screens match the spec?"
+  syn vision -f ./screenshots "Which of these has layout issues?"
+  syn vision --clipboard "What does this error dialog mean?"
+  grim -g "$(slurp)" - | syn vision --stdin "Explain this chart"
+  syn vision -f scan.pdf --pages 1-3 "Transcribe these pages"
 
-Supported formats: JPEG, PNG, GIF, WebP
-Accepts URLs or local file paths via -f flag.`,
+Supported formats: JPEG, PNG, GIF, WebP (detected from file contents)
+-f is repeatable and accepts URLs, local paths, globs, or directories (images
+in a directory are sent in name order). All images go in a single request,
+labelled "Image 1", "Image 2", ... Non-image files are included as text context.
+
+PDFs are read with poppler-utils: pages with a text layer are sent as text and
+scanned pages are rasterised and sent as images. --pages selects pages.
+
+--clipboard reads an image from the clipboard (wl-paste on Wayland, xclip on
+X11); --stdin reads raw image bytes from a pipe. Both combine with -f.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		imageSource := viper.GetString("file")
-		if imageSource == "" {
-			return fmt.Errorf("image required: use -f <image>")
+		images, texts, err := expandVisionInputs(inputFiles())
+		if err != nil {
+			return err
+		}
+
+		captured, err := captureVisionImages(cmd.Context())
+		if err != nil {
+			return err
+		}
+		images = append(captured, images...)
+
+		hasPDF := slices.ContainsFunc(texts, document.IsPDF)
+		if len(images) == 0 && !hasPDF {
+			return fmt.Errorf("image required: use -f <image|pdf>, --clipboard or --stdin")
 		}
 
 		prompt := "What do you see in this image? Please describe it in detail."
+		switch {
+		case len(images) == 0:
+			prompt = "Summarize this document, including any figures or scanned pages."
+		case len(images) > 1:
+			prompt = "Describe each of these images in detail and note how they differ."
+		}
 		if len(args) > 0 {
 			prompt = strings.Join(args, " ")
 		}
 
-		return runVision(imageSource, prompt)
+		return runVision(images, texts, prompt)
 	},
 }
 
+var ( //nolint:gochecknoglobals // cobra flag bindings require package-level vars
+	visionClipboard bool
+	visionStdin     bool
+)
+
 func init() { //nolint:gochecknoinits // cobra command registration
 	rootCmd.AddCommand(visionCmd)
+	visionCmd.Flags().BoolVar(&visionClipboard, "clipboard", false, "read an image from the system clipboard")
+	visionCmd.Flags().BoolVar(&visionStdin, "stdin", false, "read raw image bytes from stdin")
+}
+
+// captureVisionImages returns data: URLs for --clipboard and --stdin images.
+func captureVisionImages(ctx context.Context) ([]string, error) {
+	var images []string
+
+	if visionClipboard {
+		data, err := clipboard.ReadImage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("clipboard: %w", err)
+		}
+		url, err := app.ImageDataURL(data)
+		if err != nil {
+			return nil, fmt.Errorf("clipboard: %w", err)
+		}
+		images = append(images, url)
+	}
+
+	if visionStdin {
+		if !hasStdinData() {
+			return nil, fmt.Errorf("--stdin: no image data piped in")
+		}
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stdin: %w", err)
+		}
+		url, err := app.ImageDataURL(data)
+		if err != nil {
+			return nil, fmt.Errorf("stdin: %w", err)
+		}
+		images = append(images, url)
+	}
+
+	return images, nil
 }
 
-func runVision(imageSource, prompt string) error {
+// displaySource shortens data: URLs for logs and verbose output.
+func displaySource(src string) string {
+	rest, ok := strings.CutPrefix(src, "data:")
+	if !ok {
+		return src
+	}
+	mimeType, _, _ := strings.Cut(rest, ";")
+	return fmt.Sprintf("<%s data URL, %d bytes>", mimeType, len(src))
+}
+
+// expandVisionInputs resolves -f values into image sources and text context
+// files. URLs are kept as images; globs and directories are expanded, with
+// directories contributing only their (non-hidden) image files.
+func expandVisionInputs(inputs []string) (images, texts []string, err error) {
+	classify := func(path string) {
+		if app.IsImagePath(path) {
+			images = append(images, path)
+		} else {
+			texts = append(texts, path)
+		}
+	}
+
+	for _, in := range inputs {
+		if strings.HasPrefix(in, "http://") || strings.HasPrefix(in, "https://") {
+			images = append(images, in)
+			continue
+		}
+
+		if strings.ContainsAny(in, "*?[") {
+			matches, err := filepath.Glob(in)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid pattern %q: %w", in, err)
+			}
+			if len(matches) == 0 {
+				return nil, nil, fmt.Errorf("no files match %q", in)
+			}
+			for _, m := range matches {
+				classify(m)
+			}
+			continue
+		}
+
+		info, err := os.Stat(in)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %w", in, err)
+		}
+		if !info.IsDir() {
+			classify(in)
+			continue
+		}
+
+		entries, err := os.ReadDir(in)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read directory %s: %w", in, err)
+		}
+		found := 0
+		for _, e := range entries {
+			if e.IsDir() || strings.HasPrefix(e.Name(), ".") || !app.IsImagePath(e.Name()) {
+				continue
+			}
+			images = append(images, filepath.Join(in, e.Name()))
+			found++
+		}
+		if found == 0 {
+			return nil, nil, fmt.Errorf("no images in directory %s", in)
+		}
+	}
+	return images, texts, nil
+}
+
+func runVision(images, texts []string, prompt string) error {
 	client := newClient()
 	opts := app.DefaultChatOptions()
+	opts.Files = texts
+	opts.Pages = pagesFlag
 
+	model := client.VisionModel()
 	if m := viper.GetString("model"); m != "" {
 		opts.Model = m
+		model = app.ResolveModel(m)
 	}
 
 	if viper.GetBool("verbose") {
-		fmt.Fprintf(os.Stderr, "Image: %s\n", imageSource)
+		for _, img := range images {
+			fmt.Fprintf(os.Stderr, "Image: %s\n", displaySource(img))
+		}
+		for _, f := range texts {
+			fmt.Fprintf(os.Stderr, "File: %s\n", f)
+		}
 		fmt.Fprintf(os.Stderr, "Prompt: %s\n", prompt)
-		fmt.Fprintf(os.Stderr, "Model: %s\n", app.ResolveModel("kimi"))
+		fmt.Fprintf(os.Stderr, "Model: %s\n", model)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
-	response, err := client.Vision(ctx, prompt, imageSource, opts)
+	response, usage, err := client.Vision(ctx, prompt, images, opts)
 	if err != nil {
 		return fmt.Errorf("vision failed: %w", err)
 	}
 
 	fmt.Println(response)
+	printUsageNotes(usage)
 	return nil
 }
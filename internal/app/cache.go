@@ -0,0 +1,104 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/dotcommander/syn/internal/cache"
+)
+
+// CacheMode controls how the response caches are used.
+type CacheMode int
+
+const (
+	CacheOn      CacheMode = iota // serve hits and store responses
+	CacheRefresh                  // skip reads, store fresh responses
+	CacheOff                      // neither read nor write
+)
+
+// chatCacheEntry is a cached chat completion.
+type chatCacheEntry struct {
+	Content string `json:"content"`
+	Model   string `json:"model"`
+	Usage   Usage  `json:"usage"`
+}
+
+func (e chatCacheEntry) usage() Usage {
+	u := e.Usage
+	u.Model = e.Model
+	u.Cached = true
+	return u
+}
+
+// cacheKey hashes the JSON encoding of v under a kind prefix.
+func cacheKey(kind string, v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return kind + ":" + hex.EncodeToString(sum[:])
+}
+
+// scopedKey pairs a request with the endpoint and profile it was sent under,
+// so profiles pointing at different APIs never share cache entries.
+type scopedKey struct {
+	BaseURL string `json:"base_url"`
+	Profile string `json:"profile"`
+	Request any    `json:"request"`
+}
+
+func (c *Client) scoped(v any) scopedKey {
+	return scopedKey{BaseURL: c.config.BaseURL, Profile: c.config.Profile, Request: v}
+}
+
+// chatCacheKey keys a chat request by endpoint, profile, model, messages and
+// sampling params, or returns "" when the request is not cached.
+func (c *Client) chatCacheKey(messages []Message, opts ChatOptions) string {
+	if c.config.Cache == nil || opts.NoCache || c.config.CacheMode == CacheOff {
+		return ""
+	}
+	return cacheKey("chat", c.scoped(c.buildChatRequest(messages, opts)))
+}
+
+// embedCacheKey keys one input's embedding by endpoint, profile, model and text.
+func (c *Client) embedCacheKey(model, text string) string {
+	if c.config.Cache == nil || c.config.CacheMode == CacheOff {
+		return ""
+	}
+	return cacheKey("embed", c.scoped([2]string{model, text}))
+}
+
+// searchCacheKey keys a query by endpoint and profile, normalizing it so
+// trivially different spellings share an entry.
+func (c *Client) searchCacheKey(query string) string {
+	if c.config.SearchCache == nil || c.config.CacheMode == CacheOff {
+		return ""
+	}
+	return cacheKey("search", c.scoped(strings.Join(strings.Fields(strings.ToLower(query)), " ")))
+}
+
+// cacheGet loads key from store into v. Refresh mode, an empty key and read
+// failures are misses; failures are logged, never returned.
+func (c *Client) cacheGet(store *cache.Cache, key string, v any) cache.Info {
+	if key == "" || c.config.CacheMode == CacheRefresh {
+		return cache.Info{}
+	}
+	info, err := store.Get(key, v)
+	if err != nil {
+		c.logger.Debug("cache read failed", "key", key, "error", err)
+	}
+	return info
+}
+
+// cachePut stores v under key, logging failures.
+func (c *Client) cachePut(store *cache.Cache, key string, v any) {
+	if key == "" {
+		return
+	}
+	if err := store.Put(key, v); err != nil {
+		c.logger.Debug("cache write failed", "key", key, "error", err)
+	}
+}
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dotcommander/syn/internal/cache"
+)
+
+func TestChatCache(t *testing.T) {
+	store := cache.New(t.TempDir(), time.Hour)
+	body := `{"choices":[{"message":{"role":"assistant","content":"ok"}}],"usage":{"total_tokens":7}}`
+	doer := &scriptDoer{script: []func() (*http.Response, error){status(http.StatusOK, body)}}
+	cfg := ClientConfig{APIKey: "k", Model: "deepseek", Cache: store}
+	ctx := context.Background()
+
+	c := NewClient(cfg, NewLogger(false), doer)
+	for range 2 {
+		resp, usage, err := c.Chat(ctx, "hi", ChatOptions{})
+		if err != nil || resp != "ok" || usage.TotalTokens != 7 || usage.Model != ResolveModel("deepseek") {
+			t.Fatalf("Chat = %q, %+v, %v", resp, usage, err)
+		}
+	}
+	if doer.calls != 1 {
+		t.Fatalf("calls = %d, want 1", doer.calls)
+	}
+
+	// Streams share entries with Chat; a cached stream has no TTFT.
+	sr, err := c.ChatStream(ctx, "hi", ChatOptions{})
+	if err != nil || sr.Content != "ok" || !sr.Usage.Cached || sr.TTFMS != 0 || doer.calls != 1 {
+		t.Fatalf("ChatStream = %+v, %v after %d calls", sr, err, doer.calls)
+	}
+
+	// Different sampling params, NoCache and refresh mode all reach the API.
+	if _, _, err := c.Chat(ctx, "hi", ChatOptions{Temperature: Float64Ptr(0)}); err != nil || doer.calls != 2 {
+		t.Fatalf("temperature change: %v after %d calls", err, doer.calls)
+	}
+	if _, usage, _ := c.Chat(ctx, "hi", ChatOptions{NoCache: true}); usage.Cached || doer.calls != 3 {
+		t.Fatalf("NoCache served from cache after %d calls", doer.calls)
+	}
+	cfg.CacheMode = CacheRefresh
+	if _, usage, _ := NewClient(cfg, NewLogger(false), doer).Chat(ctx, "hi", ChatOptions{}); usage.Cached || doer.calls != 4 {
+		t.Fatalf("refresh served from cache after %d calls", doer.calls)
+	}
+}
+
+func TestEmbeddingCachePerInput(t *testing.T) {
+	doer := &embedDoer{}
+	c := NewClient(ClientConfig{APIKey: "k", Cache: cache.New(t.TempDir(), time.Hour)}, NewLogger(false), doer)
+	ctx := context.Background()
+
+	if _, err := c.Embed(ctx, []string{"a", "bbb"}, "m"); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Embed(ctx, []string{"cc", "bbb", "a"}, "m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doer.calls.Load() != 2 || resp.Usage.TotalTokens != 1 { // only "cc" was sent
+		t.Fatalf("calls = %d, usage = %+v", doer.calls.Load(), resp.Usage)
+	}
+	for i, want := range []float64{2, 3, 1} {
+		if d := resp.Data[i]; d.Index != i || d.Embedding[0] != want {
+			t.Fatalf("data[%d] = %+v, want %v", i, d, want)
+		}
+	}
+
+	if _, err := c.Embed(ctx, []string{"a"}, "other-model"); err != nil || doer.calls.Load() != 3 {
+		t.Fatalf("model change: %v after %d calls", err, doer.calls.Load())
+	}
+}
+
+func TestSearchCache(t *testing.T) {
+	doer := &scriptDoer{script: []func() (*http.Response, error){status(http.StatusOK, `{"results":[{"title":"t","url":"https://x"}]}`)}}
+	c := NewClient(ClientConfig{APIKey: "k", SearchCache: cache.New(t.TempDir(), time.Hour)}, NewLogger(false), doer)
+
+	first, err := c.Search(context.Background(), "Go  generics")
+	if err != nil || first.Cache.Hit || first.Cache.TTL != time.Hour {
+		t.Fatalf("first = %+v, %v", first, err)
+	}
+	second, err := c.Search(context.Background(), "go generics")
+	if err != nil || !second.Cache.Hit || len(second.Results) != 1 || doer.calls != 1 {
+		t.Fatalf("second = %+v, %v after %d calls", second, err, doer.calls)
+	}
+
+	// Another endpoint or profile misses the cache.
+	for _, cfg := range []ClientConfig{
+		{APIKey: "k", BaseURL: "https://other.example/v1", SearchCache: c.config.SearchCache},
+		{APIKey: "k", Profile: "work", SearchCache: c.config.SearchCache},
+	} {
+		res, err := NewClient(cfg, NewLogger(false), doer).Search(context.Background(), "go generics")
+		if err != nil || res.Cache.Hit {
+			t.Fatalf("%+v: hit = %v, %v", cfg, res.Cache.Hit, err)
+		}
+	}
+	if doer.calls != 3 {
+		t.Fatalf("calls = %d, want 3", doer.calls)
+	}
+}
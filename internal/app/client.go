@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"bytes"
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,9 +12,11 @@ import (
 	"math/rand/v2"
 	"net/http"
 	"os"
-	"path/filepath"
+	"slices"
 	"strings"
 	"time"
+
+	"github.com/dotcommander/syn/internal/catalog"
 )
 
 // ChatClient interface for testability (ISP compliance).
@@ -33,9 +34,14 @@ type EmbeddingClient interface {
 	Embed(ctx context.Context, texts []string, model string) (*EmbeddingResponse, error)
 }
 
+// BatchEmbeddingClient interface for large, chunked embedding jobs (ISP compliance).
+type BatchEmbeddingClient interface {
+	EmbedBatch(ctx context.Context, texts []string, opts EmbedBatchOptions) (*EmbedBatchResult, error)
+}
+
 // VisionClient interface for vision/image analysis (ISP compliance).
 type VisionClient interface {
-	Vision(ctx context.Context, prompt string, imageSource string, opts ChatOptions) (string, error)
+	Vision(ctx context.Context, prompt string, images []string, opts ChatOptions) (string, Usage, error)
 }
 
 // SearchClient interface for web search (ISP compliance).
@@ -43,6 +49,9 @@ type SearchClient interface {
 	Search(ctx context.Context, query string) (*SearchResponse, error)
 }
 
+// DefaultTimeout bounds each HTTP request when ClientConfig.Timeout is unset.
+const DefaultTimeout = 60 * time.Second
+
 // HTTPDoer interface for HTTP operations (DIP compliance, enables testing).
 type HTTPDoer interface {
 	Do(req *http.Request) (*http.Response, error)
@@ -53,22 +62,29 @@ type Client struct {
 	config     ClientConfig
 	httpClient HTTPDoer
 	logger     *slog.Logger
+	prepared   preparedSet // digests of data: URLs returned by prepareImage, resent as-is
 }
 
-// NewClient creates a client with injected dependencies.
+// NewClient creates a client with injected dependencies. httpClient is
+// wrapped in a ResilientDoer configured by cfg.RetryConfig and
+// cfg.CircuitBreaker, so every endpoint shares one retry policy. With
+// cfg.RateLimiter set, every attempt first waits for the model's budget.
+// Each request, retries included, is traced and measured through
+// cfg.TracerProvider and cfg.MeterProvider, or the otel globals.
 func NewClient(cfg ClientConfig, logger *slog.Logger, httpClient HTTPDoer) *Client {
 	timeout := cfg.Timeout
 	if timeout == 0 {
-		timeout = 60 * time.Second
+		timeout = DefaultTimeout
 	}
 
 	if httpClient == nil {
 		httpClient = &http.Client{Timeout: timeout}
 	}
 
+	resilient := NewResilientDoer(withRateLimit(httpClient, cfg.RateLimiter, logger), cfg.RetryConfig, cfg.CircuitBreaker, logger)
 	return &Client{
 		config:     cfg,
-		httpClient: httpClient,
+		httpClient: newTelemetryDoer(resilient, cfg.TracerProvider, cfg.MeterProvider),
 		logger:     logger,
 	}
 }
@@ -97,13 +113,37 @@ func (c *Client) ChatStream(ctx context.Context, prompt string, opts ChatOptions
 		return StreamResult{}, err
 	}
 
-	content, err := c.buildContent(prompt, opts.FilePath)
+	content, pages, err := c.buildContent(ctx, prompt, opts)
 	if err != nil {
 		return StreamResult{}, err
 	}
+	opts.Images = appendAttachments(opts.Images, pages)
 
-	messages := c.buildMessagesWithContext(content, opts)
-	return c.doStreamRequest(ctx, messages, opts)
+	messages, err := c.resolveImageParts(c.buildMessagesWithContext(content, opts))
+	if err != nil {
+		return StreamResult{}, err
+	}
+	if opts.NoRetry {
+		ctx = withoutRetry(ctx)
+	}
+	var result StreamResult
+	from, err := c.withFallback(ctx, messages, c.withVisionModel(messages, opts), func(opts ChatOptions) error {
+		key := c.chatCacheKey(messages, opts)
+		var hit chatCacheEntry
+		if c.cacheGet(c.config.Cache, key, &hit).Hit {
+			result = StreamResult{Content: hit.Content, Usage: hit.usage()}
+			return nil
+		}
+
+		var err error
+		result, err = c.doStreamRequest(ctx, messages, opts)
+		if err == nil {
+			c.cachePut(c.config.Cache, key, chatCacheEntry{Content: result.Content, Model: result.Usage.Model, Usage: result.Usage})
+		}
+		return err
+	})
+	result.Usage.FallbackFrom = from
+	return result, err
 }
 
 // Chat sends a prompt and returns the response with token usage.
@@ -112,17 +152,23 @@ func (c *Client) Chat(ctx context.Context, prompt string, opts ChatOptions) (str
 		return "", Usage{}, err
 	}
 
-	// Build message content (with optional file)
-	content, err := c.buildContent(prompt, opts.FilePath)
+	// Build message content (with optional files; scanned PDF pages become images)
+	content, pages, err := c.buildContent(ctx, prompt, opts)
 	if err != nil {
 		return "", Usage{}, err
 	}
+	opts.Images = appendAttachments(opts.Images, pages)
 
-	// Build messages array with context
-	messages := c.buildMessagesWithContext(content, opts)
+	// Build messages array with context, encoding any local images
+	messages, err := c.resolveImageParts(c.buildMessagesWithContext(content, opts))
+	if err != nil {
+		return "", Usage{}, err
+	}
 
-	// Execute request with retry
-	response, usage, err := c.doRequestWithRetry(ctx, messages, opts)
+	if opts.NoRetry {
+		ctx = withoutRetry(ctx)
+	}
+	response, usage, err := c.doRequestWithFallback(ctx, messages, c.withVisionModel(messages, opts))
 	if err != nil {
 		return "", Usage{}, err
 	}
@@ -135,25 +181,26 @@ func (c *Client) Chat(ctx context.Context, prompt string, opts ChatOptions) (str
 	return response, usage, nil
 }
 
-// buildContent combines prompt with optional file contents.
-func (c *Client) buildContent(prompt, filePath string) (string, error) {
-	if filePath == "" {
-		return prompt, nil
-	}
-
-	// Read local file
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to read file %s: %w", filePath, err)
-	}
-
-	return fmt.Sprintf("%s\n\nFile contents (%s):\n```\n%s\n```", prompt, filePath, string(data)), nil
-}
-
 // buildMessagesWithContext constructs messages array including conversation context.
 func (c *Client) buildMessagesWithContext(content string, opts ChatOptions) []Message {
 	messages := c.buildMessages(content)
 
+	// Attach images to the user message
+	if len(opts.Images) > 0 {
+		user := &messages[len(messages)-1]
+		for _, img := range opts.Images {
+			user.Parts = append(user.Parts, ImagePart(img))
+		}
+		user.Parts = append(user.Parts, TextPart(user.Content))
+	}
+
+	// Insert retrieved sources just before the current user message
+	if len(opts.Sources) > 0 {
+		last := len(messages) - 1
+		sourcesMsg := Message{Role: "system", Content: buildSourcesPrompt(opts.Sources)}
+		messages = append(messages[:last], sourcesMsg, messages[last])
+	}
+
 	// Prepend context messages if provided
 	if len(opts.Context) > 0 {
 		messages = append(opts.Context, messages...)
@@ -162,6 +209,61 @@ func (c *Client) buildMessagesWithContext(content string, opts ChatOptions) []Me
 	return messages
 }
 
+// withVisionModel selects VisionModel when messages carry images and no
+// model was requested.
+func (c *Client) withVisionModel(messages []Message, opts ChatOptions) ChatOptions {
+	if opts.Model == "" && hasImages(messages) {
+		opts.Model = c.VisionModel()
+	}
+	return opts
+}
+
+// hasImages reports whether any message carries an image part.
+func hasImages(messages []Message) bool {
+	for _, m := range messages {
+		if len(m.Images()) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// VisionModel returns the model used for image requests: the configured
+// vision model, else the chat model when the catalog marks it vision-capable,
+// else the first vision-capable catalog entry, else DefaultVisionModel.
+func (c *Client) VisionModel() string {
+	if c.config.VisionModel != "" {
+		return ResolveModel(c.config.VisionModel)
+	}
+	cat := c.catalog()
+	if m := ResolveModel(c.config.Model); m != "" && cat.SupportsVision(m) {
+		return m
+	}
+	if ids := cat.VisionModels(); len(ids) > 0 {
+		return ids[0]
+	}
+	return ResolveModel(DefaultVisionModel)
+}
+
+// catalog returns the configured capability catalog or the built-in table.
+func (c *Client) catalog() *catalog.Catalog {
+	if c.config.Catalog != nil {
+		return c.config.Catalog
+	}
+	return catalog.Builtin()
+}
+
+// buildSourcesPrompt formats retrieved chunks as numbered, attributed context.
+func buildSourcesPrompt(sources []Source) string {
+	var b strings.Builder
+	b.WriteString("Answer using the numbered sources below when they are relevant. ")
+	b.WriteString("Cite them inline as [n]. If the sources do not contain the answer, say so.\n")
+	for i, s := range sources {
+		fmt.Fprintf(&b, "\n[%d] %s\n%s\n", i+1, s.Ref, s.Text)
+	}
+	return b.String()
+}
+
 // buildMessages constructs the messages array for the API.
 func (c *Client) buildMessages(content string) []Message {
 	var messages []Message
@@ -212,92 +314,103 @@ func (c *Client) doRequest(ctx context.Context, messages []Message, opts ChatOpt
 		return "", Usage{}, fmt.Errorf("no choices in response")
 	}
 
-	return chatResp.Choices[0].Message.Content, chatResp.Usage, nil
+	usage := chatResp.Usage
+	usage.Model = reqData.Model
+	return chatResp.Choices[0].Message.Content, usage, nil
 }
 
-// doRequestWithRetry executes doRequest with exponential backoff retry logic.
-func (c *Client) doRequestWithRetry(ctx context.Context, messages []Message, opts ChatOptions) (string, Usage, error) {
-	var lastErr error
-
-	maxAttempts := max(c.config.RetryConfig.MaxAttempts, 1)
-
-	initialBackoff := c.config.RetryConfig.InitialBackoff
-	if initialBackoff < 1 {
-		initialBackoff = 1 * time.Second
-	}
-
-	maxBackoff := c.config.RetryConfig.MaxBackoff
-	if maxBackoff < 1 {
-		maxBackoff = 30 * time.Second
-	}
-
-	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		select {
-		case <-ctx.Done():
-			return "", Usage{}, ctx.Err()
-		default:
+// doRequestWithFallback executes doRequest, moving along the model's fallback
+// chain when retries are exhausted. Each model's request is served from the
+// response cache when possible.
+func (c *Client) doRequestWithFallback(ctx context.Context, messages []Message, opts ChatOptions) (string, Usage, error) {
+	var response string
+	var usage Usage
+
+	from, err := c.withFallback(ctx, messages, opts, func(opts ChatOptions) error {
+		key := c.chatCacheKey(messages, opts)
+		var hit chatCacheEntry
+		if c.cacheGet(c.config.Cache, key, &hit).Hit {
+			response, usage = hit.Content, hit.usage()
+			return nil
 		}
 
-		if attempt > 1 {
-			backoff := calculateBackoff(attempt, initialBackoff, maxBackoff)
-			c.logger.Debug("retrying request",
-				"attempt", attempt,
-				"max_attempts", maxAttempts,
-				"backoff", backoff,
-				"error", lastErr)
-
-			select {
-			case <-time.After(backoff):
-			case <-ctx.Done():
-				return "", Usage{}, ctx.Err()
-			}
+		var err error
+		response, usage, err = c.doRequest(ctx, messages, opts)
+		if err == nil {
+			c.cachePut(c.config.Cache, key, chatCacheEntry{Content: response, Model: usage.Model, Usage: usage})
 		}
+		return err
+	})
+	if err != nil {
+		return "", Usage{}, err
+	}
+	usage.FallbackFrom = from
+	return response, usage, nil
+}
 
-		response, usage, err := c.doRequest(ctx, messages, opts)
+// withFallback runs fn with each model of the requested model's fallback
+// chain in turn, moving on only after a retryable failure (unavailable,
+// overloaded or timed out) or when the model's circuit is open. When a
+// fallback succeeds it returns the model originally requested. Requests
+// carrying images only fall back to models the catalog marks vision-capable.
+func (c *Client) withFallback(ctx context.Context, messages []Message, opts ChatOptions, fn func(ChatOptions) error) (string, error) {
+	requested := opts.Model
+	if requested == "" {
+		requested = c.config.Model
+	}
+	chain := c.FallbackChain(requested)
+	switch {
+	case opts.NoFallback:
+		chain = chain[:1]
+	case hasImages(messages):
+		cat := c.catalog()
+		chain = append(chain[:1], slices.DeleteFunc(chain[1:], func(m string) bool {
+			return !cat.SupportsVision(m)
+		})...)
+	}
+
+	for i, model := range chain {
+		opts.Model = model
+		err := fn(opts)
 		if err == nil {
-			return response, usage, nil
+			if i > 0 {
+				return chain[0], nil
+			}
+			return "", nil
 		}
-
-		lastErr = err
-
-		if !isRetryableError(err) || attempt == maxAttempts {
-			break
+		if i == len(chain)-1 || ctx.Err() != nil || !(isRetryableError(err) || errors.Is(err, ErrCircuitOpen)) {
+			if i > 0 {
+				return "", fmt.Errorf("%s (fallback from %s): %w", model, chain[0], err)
+			}
+			return "", err
 		}
+		c.logger.Warn("model unavailable, falling back", "model", model, "next", chain[i+1], "error", err)
 	}
-
-	return "", Usage{}, fmt.Errorf("request failed after %d attempts: %w", maxAttempts, lastErr)
+	return "", nil
 }
 
-// isRetryableError checks if an error should trigger a retry.
-func isRetryableError(err error) bool {
-	if err == nil {
-		return false
-	}
-
-	var netErr interface{ Timeout() bool }
-	if errors.As(err, &netErr) && netErr.Timeout() {
-		return true
-	}
-
-	errStr := err.Error()
-	retryablePatterns := []string{
-		"connection refused",
-		"connection reset",
-		"temporary failure",
-		"timeout",
-		"429", // Too Many Requests
-		"503", // Service Unavailable
-		"502", // Bad Gateway
-		"504", // Gateway Timeout
+// FallbackChain returns the models to try for model, starting with model
+// itself. Chains are configured per alias or full model ID and match any name
+// that resolves to the same model; aliases in a chain are resolved and
+// repeated models dropped.
+func (c *Client) FallbackChain(model string) []string {
+	resolved := ResolveModel(model)
+	chain := []string{resolved}
+	next, ok := c.config.Fallbacks[model]
+	if !ok {
+		for key, models := range c.config.Fallbacks {
+			if ResolveModel(key) == resolved {
+				next = models
+				break
+			}
+		}
 	}
-
-	for _, pattern := range retryablePatterns {
-		if strings.Contains(strings.ToLower(errStr), pattern) {
-			return true
+	for _, m := range next {
+		if m = ResolveModel(m); !slices.Contains(chain, m) {
+			chain = append(chain, m)
 		}
 	}
-
-	return false
+	return chain
 }
 
 // calculateBackoff calculates exponential backoff with jitter.
@@ -324,6 +437,7 @@ func (c *Client) doStreamRequest(ctx context.Context, messages []Message, opts C
 		return StreamResult{}, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	ctx, clock := withAttemptClock(ctx)
 	url := fmt.Sprintf("%s/chat/completions", c.config.BaseURL)
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
@@ -334,7 +448,6 @@ func (c *Client) doStreamRequest(ctx context.Context, messages []Message, opts C
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.APIKey))
 	req.Header.Set("Accept", "text/event-stream")
 
-	started := time.Now()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return StreamResult{}, fmt.Errorf("failed to send request: %w", err)
@@ -343,14 +456,19 @@ func (c *Client) doStreamRequest(ctx context.Context, messages []Message, opts C
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return StreamResult{}, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+		return StreamResult{}, newAPIError(resp, body)
 	}
 
-	return c.readSSEStream(resp.Body, started)
+	result, err := c.readSSEStream(resp.Body, clock)
+	result.Usage.Model = reqData.Model
+	result.WaitMS = clock.Wait().Milliseconds()
+	return result, err
 }
 
-// readSSEStream reads SSE events from a streaming response body.
-func (c *Client) readSSEStream(body io.Reader, started time.Time) (StreamResult, error) {
+// readSSEStream reads SSE events from a streaming response body. TTFT is
+// measured from the start of the final attempt, which may change while
+// reading if the stream is re-sent before its first token.
+func (c *Client) readSSEStream(body io.Reader, clock *attemptClock) (StreamResult, error) {
 	var result StreamResult
 	var content strings.Builder
 	gotFirstToken := false
@@ -381,7 +499,7 @@ func (c *Client) readSSEStream(body io.Reader, started time.Time) (StreamResult,
 		for _, choice := range chunk.Choices {
 			if choice.Delta.Content != "" {
 				if !gotFirstToken {
-					result.TTFMS = time.Since(started).Milliseconds()
+					result.TTFMS = time.Since(clock.Start()).Milliseconds()
 					gotFirstToken = true
 				}
 				content.WriteString(choice.Delta.Content)
@@ -445,12 +563,23 @@ func (c *Client) doHTTPRequest(req *http.Request, contentType string) ([]byte, e
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+		return nil, newAPIError(resp, body)
 	}
 
 	return body, nil
 }
 
+// Ping sends a one-token completion to model, without retries or fallback,
+// and returns the round-trip time, excluding any rate-limit wait.
+func (c *Client) Ping(ctx context.Context, model string) (time.Duration, error) {
+	if err := c.requireAPIKey(); err != nil {
+		return 0, err
+	}
+	ctx, clock := withAttemptClock(withoutRetry(ctx))
+	_, _, err := c.doRequest(ctx, []Message{{Role: "user", Content: "ping"}}, ChatOptions{Model: model, MaxTokens: IntPtr(1)})
+	return time.Since(clock.Start()), err
+}
+
 // ListModels fetches available models from the API.
 func (c *Client) ListModels(ctx context.Context) ([]Model, error) {
 	if err := c.requireAPIKey(); err != nil {
@@ -492,6 +621,56 @@ func (c *Client) Embed(ctx context.Context, texts []string, model string) (*Embe
 		model = c.config.EmbeddingModel
 	}
 
+	return c.embedRequest(ctx, texts, model)
+}
+
+// embedRequest embeds texts, serving each input from the response cache when
+// possible and sending the rest in a single /embeddings request. Usage counts
+// only the inputs sent.
+func (c *Client) embedRequest(ctx context.Context, texts []string, model string) (*EmbeddingResponse, error) {
+	out := &EmbeddingResponse{Object: "list", Model: model, Data: make([]EmbeddingData, len(texts))}
+	keys := make([]string, len(texts))
+	var missing []int
+	for i, text := range texts {
+		keys[i] = c.embedCacheKey(model, text)
+		out.Data[i] = EmbeddingData{Object: "embedding", Index: i}
+		if !c.cacheGet(c.config.Cache, keys[i], &out.Data[i].Embedding).Hit {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) == len(texts) {
+		return c.sendEmbeddings(ctx, texts, model, keys)
+	}
+	c.logger.Debug("embedding cache hits", "hits", len(texts)-len(missing), "inputs", len(texts))
+	if len(missing) == 0 {
+		return out, nil
+	}
+
+	subset := make([]string, len(missing))
+	subKeys := make([]string, len(missing))
+	for j, i := range missing {
+		subset[j], subKeys[j] = texts[i], keys[i]
+	}
+	resp, err := c.sendEmbeddings(ctx, subset, model, subKeys)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range resp.Data {
+		if d.Index < 0 || d.Index >= len(missing) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		out.Data[missing[d.Index]].Embedding = d.Embedding
+	}
+	if resp.Model != "" {
+		out.Model = resp.Model
+	}
+	out.Usage = resp.Usage
+	return out, nil
+}
+
+// sendEmbeddings sends a single /embeddings request and caches each vector
+// under the matching entry of keys.
+func (c *Client) sendEmbeddings(ctx context.Context, texts []string, model string, keys []string) (*EmbeddingResponse, error) {
 	reqData := EmbeddingRequest{
 		Model: model,
 		Input: texts,
@@ -524,111 +703,146 @@ func (c *Client) Embed(ctx context.Context, texts []string, model string) (*Embe
 		"embeddings", len(embedResp.Data),
 		"total_tokens", embedResp.Usage.TotalTokens)
 
+	for _, d := range embedResp.Data {
+		if d.Index >= 0 && d.Index < len(keys) {
+			c.cachePut(c.config.Cache, keys[d.Index], d.Embedding)
+		}
+	}
 	return &embedResp, nil
 }
 
-// Vision analyzes an image with a prompt using a vision-capable model.
-// imageSource can be a URL (http/https) or a local file path.
-func (c *Client) Vision(ctx context.Context, prompt string, imageSource string, opts ChatOptions) (string, error) {
+// Vision analyzes one or more images with a prompt using a vision-capable model.
+// Each image can be a URL (http/https), a data: URL or a local file path; local
+// and inline images are prepared per ClientConfig.Image. Files in opts.Files
+// are appended to the prompt as text context, and scanned PDF pages are added
+// as further images. Like Chat, it follows the model's fallback chain, skipping
+// models that are not vision-capable, and Usage reports the answering model.
+func (c *Client) Vision(ctx context.Context, prompt string, images []string, opts ChatOptions) (string, Usage, error) {
 	if err := c.requireAPIKey(); err != nil {
-		return "", err
+		return "", Usage{}, err
+	}
+	if len(images) == 0 && len(opts.Files) == 0 {
+		return "", Usage{}, fmt.Errorf("no images provided")
 	}
 
-	imageURL, err := resolveImageURL(imageSource)
+	content, pages, err := c.buildContent(ctx, prompt, opts)
 	if err != nil {
-		return "", err
+		return "", Usage{}, err
 	}
 
-	model := ResolveModel("kimi")
-	if opts.Model != "" {
-		model = ResolveModel(opts.Model)
+	labels := make([]string, 0, len(images)+len(pages))
+	for _, src := range images {
+		labels = append(labels, imageLabel(src))
+	}
+	for _, p := range pages {
+		labels = append(labels, p.label)
+	}
+	images = appendAttachments(images, pages)
+
+	imageURLs := make([]string, len(images))
+	for i, src := range images {
+		url, err := c.resolveImageURL(src)
+		if err != nil {
+			return "", Usage{}, err
+		}
+		imageURLs[i] = url
+	}
+	if err := c.checkImagePayload(imageURLs); err != nil {
+		return "", Usage{}, err
+	}
+
+	if opts.Model == "" {
+		opts.Model = c.VisionModel()
 	}
+	messages := visionMessages(labels, imageURLs, content)
 
-	reqData := buildVisionRequest(model, imageURL, prompt, opts.Temperature)
+	var response string
+	var usage Usage
+	from, err := c.withFallback(ctx, messages, opts, func(opts ChatOptions) error {
+		var err error
+		response, usage, err = c.doVisionRequest(ctx, messages, opts)
+		return err
+	})
+	if err != nil {
+		return "", Usage{}, err
+	}
+	usage.FallbackFrom = from
+	return response, usage, nil
+}
+
+// doVisionRequest sends one vision request to opts.Model.
+func (c *Client) doVisionRequest(ctx context.Context, messages []Message, opts ChatOptions) (string, Usage, error) {
+	reqData := buildVisionRequest(opts.Model, messages, opts)
 
 	jsonData, err := json.Marshal(reqData)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	url := fmt.Sprintf("%s/chat/completions", c.config.BaseURL)
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	c.logger.Debug("sending vision request", "url", url, "model", model)
+	c.logger.Debug("sending vision request", "url", url, "model", opts.Model, "images", len(messages[0].Images()))
 
 	body, err := c.doHTTPRequest(req, "application/json")
 	if err != nil {
-		return "", err
+		return "", Usage{}, err
 	}
 
-	return parseFirstChoice(body)
-}
-
-// resolveImageURL converts an image source (URL or local path) to a usable URL.
-func resolveImageURL(imageSource string) (string, error) {
-	if strings.HasPrefix(imageSource, "http://") || strings.HasPrefix(imageSource, "https://") {
-		return imageSource, nil
+	var chatResp ChatResponse
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		return "", Usage{}, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
-
-	data, err := os.ReadFile(imageSource)
-	if err != nil {
-		return "", fmt.Errorf("failed to read image file: %w", err)
+	if len(chatResp.Choices) == 0 {
+		return "", Usage{}, fmt.Errorf("no choices in response")
 	}
-
-	mimeType := imageMIMEType(filepath.Ext(imageSource))
-	return fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data)), nil
+	usage := chatResp.Usage
+	usage.Model = opts.Model
+	return chatResp.Choices[0].Message.Content, usage, nil
 }
 
-// imageMIMEType returns the MIME type for the given file extension.
-func imageMIMEType(ext string) string {
-	switch strings.ToLower(ext) {
-	case ".png":
-		return "image/png"
-	case ".gif":
-		return "image/gif"
-	case ".webp":
-		return "image/webp"
-	default:
-		return "image/jpeg"
+// visionMessages builds the single user turn of a vision request. When several
+// images are sent, each is preceded by a numbered label naming its source so
+// the prompt can refer to them ("image 1", "before.png").
+func visionMessages(labels, imageURLs []string, prompt string) []Message {
+	content := make([]ContentPart, 0, 2*len(imageURLs)+1)
+	for i, url := range imageURLs {
+		if len(imageURLs) > 1 {
+			content = append(content, TextPart(fmt.Sprintf("Image %d: %s", i+1, labels[i])))
+		}
+		content = append(content, ImagePart(url))
 	}
+	content = append(content, TextPart(prompt))
+	return []Message{{Role: "user", Content: prompt, Parts: content}}
 }
 
 // buildVisionRequest constructs the multimodal vision API request payload.
-func buildVisionRequest(model, imageURL, prompt string, temperature *float64) map[string]any {
-	content := []map[string]any{
-		{"type": "image_url", "image_url": map[string]string{"url": imageURL}},
-		{"type": "text", "text": prompt},
-	}
-
+func buildVisionRequest(model string, messages []Message, opts ChatOptions) map[string]any {
 	reqData := map[string]any{
 		"model":      model,
-		"messages":   []map[string]any{{"role": "user", "content": content}},
+		"messages":   messages,
 		"max_tokens": 4096,
 	}
 
-	if temperature != nil {
-		reqData["temperature"] = *temperature
+	if opts.MaxTokens != nil {
+		reqData["max_tokens"] = *opts.MaxTokens
 	}
-
-	return reqData
-}
-
-// parseFirstChoice unmarshals a chat response and returns the first choice content.
-func parseFirstChoice(body []byte) (string, error) {
-	var chatResp ChatResponse
-	if err := json.Unmarshal(body, &chatResp); err != nil {
-		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	if opts.Temperature != nil {
+		reqData["temperature"] = *opts.Temperature
 	}
-	if len(chatResp.Choices) == 0 {
-		return "", fmt.Errorf("no choices in response")
+	if opts.TopP != nil {
+		reqData["top_p"] = *opts.TopP
 	}
-	return chatResp.Choices[0].Message.Content, nil
+
+	return reqData
 }
 
-// Search performs a web search using the /v2/search endpoint.
+// Search performs a web search using the /v2/search endpoint. With
+// ClientConfig.SearchCache set, fresh cached results are returned and
+// SearchResponse.Cache describes the lookup.
 // Note: This API is under development and may have breaking changes.
 func (c *Client) Search(ctx context.Context, query string) (*SearchResponse, error) {
 	if err := c.requireAPIKey(); err != nil {
@@ -639,6 +853,13 @@ func (c *Client) Search(ctx context.Context, query string) (*SearchResponse, err
 		return nil, fmt.Errorf("search query cannot be empty")
 	}
 
+	key := c.searchCacheKey(query)
+	var cached SearchResponse
+	if info := c.cacheGet(c.config.SearchCache, key, &cached); info.Hit {
+		cached.Cache = info
+		return &cached, nil
+	}
+
 	reqData := SearchRequest{Query: query}
 	jsonData, err := json.Marshal(reqData)
 	if err != nil {
@@ -666,5 +887,9 @@ func (c *Client) Search(ctx context.Context, query string) (*SearchResponse, err
 
 	c.logger.Debug("search complete", "results", len(searchResp.Results))
 
+	if key != "" {
+		c.cachePut(c.config.SearchCache, key, searchResp)
+		searchResp.Cache.TTL = c.config.SearchCache.TTL()
+	}
 	return &searchResp, nil
 }
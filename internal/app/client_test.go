@@ -0,0 +1,426 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dotcommander/syn/internal/imgprep"
+	"github.com/dotcommander/syn/internal/textfile"
+)
+
+func writeTestPNG(t *testing.T, name string, w, h int) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, image.NewGray(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBuildMessagesWithSources(t *testing.T) {
+	c := NewClient(ClientConfig{}, NewLogger(false), nil)
+	opts := ChatOptions{
+		Context: []Message{{Role: "user", Content: "earlier"}, {Role: "assistant", Content: "reply"}},
+		Sources: []Source{{Ref: "docs/run.md:1-5", Text: "restart the worker"}},
+	}
+
+	msgs := c.buildMessagesWithContext("how do I restart?", opts)
+	if len(msgs) != 5 {
+		t.Fatalf("expected 5 messages, got %d", len(msgs))
+	}
+
+	sources := msgs[3]
+	if sources.Role != "system" || !strings.Contains(sources.Content, "[1] docs/run.md:1-5") {
+		t.Fatalf("expected attributed sources before user turn, got %+v", sources)
+	}
+	if last := msgs[4]; last.Role != "user" || last.Content != "how do I restart?" {
+		t.Fatalf("expected user turn last, got %+v", last)
+	}
+}
+
+func TestBuildVisionRequestMultipleImages(t *testing.T) {
+	opts := ChatOptions{MaxTokens: IntPtr(1024), TopP: Float64Ptr(0.5)}
+	req := buildVisionRequest("m", visionMessages([]string{"before.png", "after.png"},
+		[]string{"data:a", "data:b"}, "what changed?"), opts)
+
+	if req["max_tokens"] != 1024 || req["top_p"] != 0.5 {
+		t.Fatalf("expected options to be honoured, got max_tokens=%v top_p=%v", req["max_tokens"], req["top_p"])
+	}
+
+	content := req["messages"].([]Message)[0].Parts
+	if len(content) != 5 {
+		t.Fatalf("expected 2 labels, 2 images and the prompt, got %d parts", len(content))
+	}
+	if content[0].Text != "Image 1: before.png" || content[2].Text != "Image 2: after.png" {
+		t.Fatalf("unexpected image labels: %v, %v", content[0], content[2])
+	}
+	if content[4].Text != "what changed?" {
+		t.Fatalf("expected prompt last, got %v", content[4])
+	}
+}
+
+func TestBuildContentMultipleFiles(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")
+	if err := os.WriteFile(a, []byte("alpha"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte("beta"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewClient(ClientConfig{}, NewLogger(false), nil)
+	content, _, err := c.buildContent(context.Background(), "compare", ChatOptions{Files: []string{a, b}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(content, "compare") || strings.Index(content, "alpha") > strings.Index(content, "beta") {
+		t.Fatalf("expected prompt then files in order, got %q", content)
+	}
+}
+
+func TestMessageJSON(t *testing.T) {
+	plain, err := json.Marshal(Message{Role: "user", Content: "hi"})
+	if err != nil || string(plain) != `{"role":"user","content":"hi"}` {
+		t.Fatalf("plain message = %s, %v", plain, err)
+	}
+
+	multi := Message{Role: "user", Content: "what is this?", Parts: []ContentPart{ImagePart("https://x/a.png"), TextPart("what is this?")}}
+	data, err := json.Marshal(multi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"role":"user","content":[{"type":"image_url","image_url":{"url":"https://x/a.png"}},{"type":"text","text":"what is this?"}]}`
+	if string(data) != want {
+		t.Fatalf("multimodal message = %s", data)
+	}
+
+	var back Message
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Content != "what is this?" || len(back.Images()) != 1 || back.Images()[0] != "https://x/a.png" {
+		t.Fatalf("round trip = %+v", back)
+	}
+}
+
+func TestResolveImagePartsKeepsHistory(t *testing.T) {
+	path := writeTestPNG(t, "shot.png", 8, 8)
+
+	history := []Message{
+		{Role: "user", Content: "look", Parts: []ContentPart{ImagePart(path), TextPart("look")}},
+		{Role: "assistant", Content: "a button"},
+	}
+	c := NewClient(ClientConfig{Image: ImageConfig{Options: imgprep.Options{Format: imgprep.FormatPNG}}}, NewLogger(false), nil)
+	resolved, err := c.resolveImageParts(history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resolved[0].Images()[0]; !strings.HasPrefix(got, "data:image/png;base64,") {
+		t.Fatalf("expected data URL, got %q", got)
+	}
+	if history[0].Images()[0] != path {
+		t.Fatalf("history was modified: %q", history[0].Images()[0])
+	}
+	if opts := c.withVisionModel(resolved, ChatOptions{}); opts.Model != c.VisionModel() {
+		t.Fatalf("expected vision model, got %q", opts.Model)
+	}
+}
+
+func TestPrepareImageSurvivesFileRemoval(t *testing.T) {
+	path := writeTestPNG(t, "shot.png", 8, 8)
+	c := NewClient(ClientConfig{}, NewLogger(false), nil)
+	url, err := c.PrepareImage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+
+	history := []Message{{Role: "user", Content: "look", Parts: []ContentPart{ImagePart(url), TextPart("look")}}}
+	for range 2 {
+		resolved, err := c.resolveImageParts(history)
+		if err != nil {
+			t.Fatalf("later turn: %v", err)
+		}
+		if got := resolved[0].Images()[0]; got != url {
+			t.Fatal("prepared image was re-encoded")
+		}
+	}
+}
+
+func TestPreparedSetIsBounded(t *testing.T) {
+	var s preparedSet
+	for i := range maxPreparedImages + 1 {
+		s.add(fmt.Sprintf("data:image/png;base64,%d", i))
+	}
+	if s.has("data:image/png;base64,0") || !s.has(fmt.Sprintf("data:image/png;base64,%d", maxPreparedImages)) {
+		t.Fatal("oldest entry should be evicted, newest kept")
+	}
+	if len(s.digests) != maxPreparedImages {
+		t.Fatalf("len = %d", len(s.digests))
+	}
+}
+
+func TestImageDataURLDetectsType(t *testing.T) {
+	url, err := ImageDataURL([]byte("GIF89a......"))
+	if err != nil || !strings.HasPrefix(url, "data:image/gif;base64,") {
+		t.Fatalf("ImageDataURL(gif) = %q, %v", url, err)
+	}
+
+	// A .jpg name no longer decides the type: contents do.
+	path := writeTestPNG(t, "shot.jpg", 8, 8)
+	c := NewClient(ClientConfig{Image: ImageConfig{Options: imgprep.Options{Format: imgprep.FormatOriginal}}}, NewLogger(false), nil)
+	if url, err := c.resolveImageURL(path); err != nil || !strings.HasPrefix(url, "data:image/png;") {
+		t.Fatalf("resolveImageURL = %q, %v", url, err)
+	}
+
+	if _, err := ImageDataURL([]byte("just some text")); err == nil {
+		t.Fatal("expected error for non-image data")
+	}
+}
+
+func TestResolveImageURLDownscalesAndGuardsSize(t *testing.T) {
+	path := writeTestPNG(t, "big.png", 300, 150)
+
+	c := NewClient(ClientConfig{Image: ImageConfig{Options: imgprep.Options{MaxDimension: 60}}}, NewLogger(false), nil)
+	url, err := c.resolveImageURL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(url, "data:image/jpeg;base64,") {
+		t.Fatalf("expected re-encoded JPEG, got %.40s", url)
+	}
+
+	c.config.Image.MaxBytes = 64
+	if err := c.checkImagePayload([]string{url}); !errors.Is(err, ErrImageTooLarge) {
+		t.Fatalf("expected ErrImageTooLarge, got %v", err)
+	}
+	if err := c.checkImagePayload([]string{"https://example.com/huge.png"}); err != nil {
+		t.Fatalf("remote URLs are not counted, got %v", err)
+	}
+}
+
+func TestBuildContentFileSafety(t *testing.T) {
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "core.dump")
+	if err := os.WriteFile(bin, []byte{0x7F, 'E', 'L', 'F', 0, 0, 0, 0, 1, 2}, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	log := filepath.Join(dir, "huge.log")
+	if err := os.WriteFile(log, []byte(strings.Repeat("request ok\n", 10_000)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewClient(ClientConfig{Files: FileConfig{Options: textfile.Options{MaxBytes: 1024}}}, NewLogger(false), nil)
+	if _, _, err := c.buildContent(context.Background(), "why?", ChatOptions{Files: []string{bin}}); !errors.Is(err, textfile.ErrBinary) {
+		t.Fatalf("expected binary file to be rejected, got %v", err)
+	}
+
+	content, _, err := c.buildContent(context.Background(), "why?", ChatOptions{Files: []string{log}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(content, "[... truncated:") || len(content) > 2048 {
+		t.Fatalf("expected truncated content, got %d bytes", len(content))
+	}
+}
+
+func TestVisionModelSelection(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  ClientConfig
+		want string
+	}{
+		{"configured", ClientConfig{VisionModel: "qwen", Model: "kimi"}, ResolveModel("qwen")},
+		{"vision-capable chat model", ClientConfig{Model: "hf:nvidia/Kimi-K2.5-NVFP4"}, "hf:nvidia/Kimi-K2.5-NVFP4"},
+		{"text chat model", ClientConfig{Model: "glm"}, "hf:moonshotai/Kimi-K2.5"},
+	}
+	for _, tt := range tests {
+		c := NewClient(tt.cfg, NewLogger(false), nil)
+		if got := c.VisionModel(); got != tt.want {
+			t.Errorf("%s: VisionModel() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestModelCapabilities(t *testing.T) {
+	var m Model
+	data := `{"id":"hf:x/y","context_length":32768,"input_modalities":["text","image"],` +
+		`"supported_features":["tools","json_mode"],"pricing":{"prompt":"$0.20","completion":0.6}}`
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		t.Fatal(err)
+	}
+	cm := m.Capabilities()
+	if !cm.Vision() || cm.ContextLength != 32768 || cm.Tools == nil || !*cm.Tools {
+		t.Fatalf("Capabilities() = %+v", cm)
+	}
+	if cm.PromptPrice != "$0.20" || cm.CompletionPrice != "0.6" {
+		t.Fatalf("prices = %q, %q", cm.PromptPrice, cm.CompletionPrice)
+	}
+}
+
+func TestUserAliasesOverrideBuiltins(t *testing.T) {
+	t.Cleanup(func() { SetUserAliases(nil) })
+	SetUserAliases(map[string]string{"glm": "hf:zai-org/GLM-5", "k2t": "hf:moonshotai/Kimi-K2-Thinking"})
+
+	if got := ResolveModel("glm"); got != "hf:zai-org/GLM-5" {
+		t.Fatalf("ResolveModel(glm) = %q", got)
+	}
+	if got := ResolveModel("k2t"); got != "hf:moonshotai/Kimi-K2-Thinking" {
+		t.Fatalf("ResolveModel(k2t) = %q", got)
+	}
+	if got := BuiltinModelAliases()["glm"]; got != "hf:zai-org/GLM-4.7" {
+		t.Fatalf("builtin glm = %q", got)
+	}
+	if _, ok := ModelAliases()["kimi"]; !ok {
+		t.Fatal("built-in aliases missing from merged map")
+	}
+}
+
+// chatDoer fakes /chat/completions, answering 503 for models in down and 400
+// for models in invalid.
+type chatDoer struct {
+	down    map[string]bool
+	invalid map[string]bool
+	tried   []string
+}
+
+func (d *chatDoer) Do(req *http.Request) (*http.Response, error) {
+	var in ChatRequest
+	if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+		return nil, err
+	}
+	d.tried = append(d.tried, in.Model)
+	if d.down[in.Model] {
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader("overloaded"))}, nil
+	}
+	if d.invalid[in.Model] {
+		return &http.Response{StatusCode: http.StatusBadRequest, Body: io.NopCloser(strings.NewReader("bad request"))}, nil
+	}
+	body := `{"choices":[{"message":{"role":"assistant","content":"ok from ` + in.Model + `"}}],"usage":{"total_tokens":3}}`
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+}
+
+func TestChatFallsBackAfterRetries(t *testing.T) {
+	doer := &chatDoer{down: map[string]bool{ResolveModel("deepseek"): true, ResolveModel("glm"): true}}
+	cfg := ClientConfig{
+		APIKey:      "k",
+		Model:       "deepseek",
+		RetryConfig: RetryConfig{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+		Fallbacks:   map[string][]string{"deepseek": {"glm", "gpt", "glm"}},
+	}
+	c := NewClient(cfg, NewLogger(false), doer)
+
+	if got := c.FallbackChain("deepseek"); len(got) != 3 || got[2] != ResolveModel("gpt") {
+		t.Fatalf("FallbackChain = %v", got)
+	}
+	if got := c.FallbackChain(ResolveModel("ds")); len(got) != 3 {
+		t.Fatalf("FallbackChain(full ID) = %v", got)
+	}
+	byID := NewClient(ClientConfig{Fallbacks: map[string][]string{"hf:moonshotai/Kimi-K2.5": {"glm"}}}, NewLogger(false), doer)
+	if got := byID.FallbackChain("kimi"); len(got) != 2 || got[1] != ResolveModel("glm") {
+		t.Fatalf("FallbackChain(kimi) with a full-ID key = %v", got)
+	}
+
+	resp, usage, err := c.Chat(context.Background(), "hi", ChatOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.Model != ResolveModel("gpt") || usage.FallbackFrom != ResolveModel("deepseek") || !strings.Contains(resp, usage.Model) {
+		t.Fatalf("answered by %q: %q", usage.Model, resp)
+	}
+	if len(doer.tried) != 5 { // 2 attempts each for deepseek and glm, then gpt
+		t.Fatalf("tried %v", doer.tried)
+	}
+
+	// Non-retryable errors do not fall back.
+	doer = &chatDoer{invalid: map[string]bool{ResolveModel("deepseek"): true}}
+	c = NewClient(cfg, NewLogger(false), doer)
+	if _, _, err := c.Chat(context.Background(), "hi", ChatOptions{}); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(doer.tried) != 1 {
+		t.Fatalf("tried %v", doer.tried)
+	}
+}
+
+func TestOpenCircuitFallsBack(t *testing.T) {
+	glm, gpt := ResolveModel("glm"), ResolveModel("gpt")
+	doer := &chatDoer{down: map[string]bool{glm: true}}
+	cfg := ClientConfig{
+		APIKey:         "k",
+		Model:          "glm",
+		RetryConfig:    RetryConfig{MaxAttempts: 1},
+		CircuitBreaker: CircuitBreakerConfig{Threshold: 1, Cooldown: time.Minute},
+		Fallbacks:      map[string][]string{"glm": {"gpt"}},
+	}
+	c := NewClient(cfg, NewLogger(false), doer)
+
+	// The first request trips glm's circuit; the second skips glm without
+	// sending it, and gpt's circuit on the same host stays closed.
+	for range 2 {
+		_, usage, err := c.Chat(context.Background(), "hi", ChatOptions{})
+		if err != nil || usage.Model != gpt {
+			t.Fatalf("answered by %q: %v", usage.Model, err)
+		}
+	}
+	if want := []string{glm, gpt, gpt}; !slices.Equal(doer.tried, want) {
+		t.Fatalf("tried %v, want %v", doer.tried, want)
+	}
+}
+
+func TestImageFallbackSkipsTextModels(t *testing.T) {
+	kimi, nvfp4 := ResolveModel("kimi"), "hf:nvidia/Kimi-K2.5-NVFP4"
+	doer := &chatDoer{down: map[string]bool{kimi: true}}
+	cfg := ClientConfig{
+		APIKey:      "k",
+		Model:       "kimi",
+		RetryConfig: RetryConfig{MaxAttempts: 1},
+		Fallbacks:   map[string][]string{"kimi": {"glm", nvfp4}},
+	}
+	c := NewClient(cfg, NewLogger(false), doer)
+
+	image := writeTestPNG(t, "shot.png", 8, 8)
+	_, usage, err := c.Chat(context.Background(), "describe", ChatOptions{Images: []string{image}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.Model != nvfp4 || len(doer.tried) != 2 {
+		t.Fatalf("answered by %q after trying %v", usage.Model, doer.tried)
+	}
+
+	// Vision follows the same filtered chain and reports the switch.
+	doer.tried = nil
+	_, usage, err = c.Vision(context.Background(), "describe", []string{image}, ChatOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.Model != nvfp4 || usage.FallbackFrom != kimi || len(doer.tried) != 2 {
+		t.Fatalf("vision answered by %q (from %q) after trying %v", usage.Model, usage.FallbackFrom, doer.tried)
+	}
+
+	// Text requests still use the whole chain.
+	doer.tried = nil
+	if _, usage, err = c.Chat(context.Background(), "hi", ChatOptions{}); err != nil || usage.Model != ResolveModel("glm") {
+		t.Fatalf("text fallback answered by %q: %v", usage.Model, err)
+	}
+}
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/dotcommander/syn/internal/document"
+	"github.com/dotcommander/syn/internal/textfile"
+)
+
+// attachment is an image produced while building content, such as a scanned
+// PDF page, together with a label for the prompt.
+type attachment struct {
+	label string
+	url   string
+}
+
+// appendAttachments returns images followed by the attachment URLs, without
+// modifying the caller's backing array.
+func appendAttachments(images []string, attachments []attachment) []string {
+	if len(attachments) == 0 {
+		return images
+	}
+	out := append([]string(nil), images...)
+	for _, a := range attachments {
+		out = append(out, a.url)
+	}
+	return out
+}
+
+// buildContent combines prompt with the contents of opts.Files. PDF pages
+// with a text layer are added as text; scanned pages are rasterised and
+// returned as image attachments for a vision model.
+func (c *Client) buildContent(ctx context.Context, prompt string, opts ChatOptions) (string, []attachment, error) {
+	var (
+		b           strings.Builder
+		attachments []attachment
+	)
+	b.WriteString(prompt)
+
+	for _, filePath := range opts.Files {
+		if document.IsPDF(filePath) {
+			pages, err := c.pdfContent(ctx, &b, filePath, opts.Pages)
+			if err != nil {
+				return "", nil, err
+			}
+			attachments = append(attachments, pages...)
+			continue
+		}
+
+		text, err := c.readFileContext(filePath)
+		if err != nil {
+			return "", nil, err
+		}
+		fmt.Fprintf(&b, "\n\nFile contents (%s):\n```\n%s\n```", filePath, text)
+	}
+	return b.String(), attachments, nil
+}
+
+// readFileContext reads a file as UTF-8 text within ClientConfig.Files limits,
+// warning when the file is large enough to dominate the prompt.
+func (c *Client) readFileContext(path string) (string, error) {
+	res, err := textfile.Read(path, c.config.Files.Options)
+	if err != nil {
+		if errors.Is(err, textfile.ErrBinary) {
+			return "", err
+		}
+		return "", fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+
+	fullTokens := int(res.Size / 4)
+	if res.Truncated {
+		c.logger.Warn("file truncated for prompt",
+			"path", path,
+			"size", textfile.FormatSize(res.Size),
+			"est_tokens", fullTokens,
+			"est_tokens_sent", EstimateTokens(res.Text))
+	} else if warn := c.config.Files.WarnTokens; warn > 0 && fullTokens > warn {
+		c.logger.Warn("large file in prompt",
+			"path", path,
+			"size", textfile.FormatSize(res.Size),
+			"est_tokens", fullTokens)
+	}
+	if res.Encoding != textfile.EncodingUTF8 {
+		c.logger.Debug("transcoded file", "path", path, "encoding", res.Encoding)
+	}
+	return res.Text, nil
+}
+
+// pdfContent writes the text pages of a PDF to b and returns its scanned
+// pages as PNG attachments.
+func (c *Client) pdfContent(ctx context.Context, b *strings.Builder, path, spec string) ([]attachment, error) {
+	pages, err := document.LoadPDF(ctx, path, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var attachments []attachment
+	for _, p := range pages {
+		if !p.Scanned() {
+			fmt.Fprintf(b, "\n\nFile contents (%s, page %d):\n```\n%s\n```", path, p.Number, p.Text)
+			continue
+		}
+		url, err := ImageDataURL(p.Image)
+		if err != nil {
+			return nil, fmt.Errorf("%s page %d: %w", path, p.Number, err)
+		}
+		label := fmt.Sprintf("%s page %d", filepath.Base(path), p.Number)
+		fmt.Fprintf(b, "\n\nFile %s, page %d: scanned page, attached as an image.", path, p.Number)
+		attachments = append(attachments, attachment{label: label, url: url})
+	}
+
+	c.logger.Debug("loaded pdf",
+		"path", path,
+		"pages", len(pages),
+		"scanned", len(attachments))
+	return attachments, nil
+}
@@ -0,0 +1,225 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// EmbedBatchOptions configures the batched embedding pipeline.
+type EmbedBatchOptions struct {
+	Model          string // embedding model (default: ClientConfig.EmbeddingModel)
+	MaxChunkTokens int    // split inputs longer than this many estimated tokens (0 = no splitting)
+	OverlapTokens  int    // estimated tokens repeated between consecutive chunks
+	BatchSize      int    // inputs per /embeddings request (default: 64)
+	Concurrency    int    // parallel requests (default: 4)
+}
+
+// DefaultEmbedBatchOptions returns defaults suited to nomic-embed-text-v1.5.
+func DefaultEmbedBatchOptions() EmbedBatchOptions {
+	return EmbedBatchOptions{
+		MaxChunkTokens: 512,
+		OverlapTokens:  64,
+		BatchSize:      64,
+		Concurrency:    4,
+	}
+}
+
+// EmbeddedChunk is one embedded piece of an input text.
+type EmbeddedChunk struct {
+	Input     int       `json:"input"` // index of the original text
+	Chunk     int       `json:"chunk"` // chunk index within that text
+	Text      string    `json:"text"`
+	Embedding []float64 `json:"embedding"`
+}
+
+// EmbedBatchResult is the ordered output of EmbedBatch.
+type EmbedBatchResult struct {
+	Model   string          `json:"model"`
+	Chunks  []EmbeddedChunk `json:"chunks"` // ordered by Input, then Chunk
+	Batches int             `json:"batches"`
+	Usage   EmbeddingUsage  `json:"usage"`
+}
+
+// EmbedBatch splits texts into token-bounded chunks, embeds them in concurrent
+// batches with retry and backoff, and reassembles the results in input order.
+func (c *Client) EmbedBatch(ctx context.Context, texts []string, opts EmbedBatchOptions) (*EmbedBatchResult, error) {
+	if err := c.requireAPIKey(); err != nil {
+		return nil, err
+	}
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("no texts provided for embedding")
+	}
+
+	model := opts.Model
+	if model == "" {
+		model = c.config.EmbeddingModel
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = 64
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+
+	var chunks []EmbeddedChunk
+	for i, text := range texts {
+		pieces := []string{text}
+		if opts.MaxChunkTokens > 0 {
+			pieces = SplitByTokens(text, opts.MaxChunkTokens, opts.OverlapTokens)
+		}
+		for j, p := range pieces {
+			chunks = append(chunks, EmbeddedChunk{Input: i, Chunk: j, Text: p})
+		}
+	}
+
+	batches := (len(chunks) + batchSize - 1) / batchSize
+	c.logger.Debug("embedding pipeline",
+		"inputs", len(texts),
+		"chunks", len(chunks),
+		"batches", batches,
+		"concurrency", concurrency)
+
+	result := &EmbedBatchResult{Model: model, Chunks: chunks, Batches: batches}
+	if err := c.runEmbedBatches(ctx, result, batchSize, concurrency); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// runEmbedBatches embeds result.Chunks in place using a bounded worker pool.
+// The first failing batch cancels the rest.
+func (c *Client) runEmbedBatches(parent context.Context, result *EmbedBatchResult, batchSize, concurrency int) error {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	starts := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+
+	for range min(concurrency, result.Batches) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range starts {
+				usage, err := c.embedChunkBatch(ctx, result.Model, result.Chunks[start:min(start+batchSize, len(result.Chunks))])
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = fmt.Errorf("embedding batch %d/%d failed: %w", start/batchSize+1, result.Batches, err)
+					cancel()
+				}
+				result.Usage.PromptTokens += usage.PromptTokens
+				result.Usage.TotalTokens += usage.TotalTokens
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for start := 0; start < len(result.Chunks); start += batchSize {
+		select {
+		case starts <- start:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(starts)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return parent.Err()
+}
+
+// embedChunkBatch embeds one batch and writes vectors back by EmbeddingData.Index.
+func (c *Client) embedChunkBatch(ctx context.Context, model string, batch []EmbeddedChunk) (EmbeddingUsage, error) {
+	texts := make([]string, len(batch))
+	for i, ch := range batch {
+		texts[i] = ch.Text
+	}
+
+	resp, err := c.embedRequest(ctx, texts, model)
+	if err != nil {
+		return EmbeddingUsage{}, err
+	}
+
+	if len(resp.Data) != len(batch) {
+		return resp.Usage, fmt.Errorf("got %d embeddings for %d inputs", len(resp.Data), len(batch))
+	}
+	for _, d := range resp.Data {
+		if d.Index < 0 || d.Index >= len(batch) {
+			return resp.Usage, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		batch[d.Index].Embedding = d.Embedding
+	}
+	return resp.Usage, nil
+}
+
+// EstimateTokens approximates the token count of s (about 4 bytes per token).
+func EstimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// SplitByTokens splits text on whitespace into chunks of at most maxTokens
+// estimated tokens, repeating up to overlap tokens of trailing words at the
+// start of each following chunk. Text that fits is returned as-is; words longer
+// than the bound are split by bytes, on rune boundaries.
+func SplitByTokens(text string, maxTokens, overlap int) []string {
+	if maxTokens <= 0 || EstimateTokens(text) <= maxTokens {
+		return []string{text}
+	}
+	overlap = max(min(overlap, maxTokens/2), 0)
+
+	// Hard-split words that alone exceed the bound (e.g. base64 blobs).
+	maxWordBytes := maxTokens * 4
+	var words []string
+	for _, w := range strings.Fields(text) {
+		for len(w) > maxWordBytes {
+			n := maxWordBytes
+			for n > 0 && !utf8.RuneStart(w[n]) {
+				n--
+			}
+			if n == 0 {
+				_, n = utf8.DecodeRuneInString(w)
+			}
+			words = append(words, w[:n])
+			w = w[n:]
+		}
+		words = append(words, w)
+	}
+
+	var chunks []string
+	var current []string
+	tokens := 0
+
+	for _, w := range words {
+		wt := EstimateTokens(w + " ")
+		if tokens > 0 && tokens+wt > maxTokens {
+			chunks = append(chunks, strings.Join(current, " "))
+
+			carried := 0
+			start := len(current)
+			for start > 0 && carried+EstimateTokens(current[start-1]+" ") <= overlap {
+				start--
+				carried += EstimateTokens(current[start] + " ")
+			}
+			current = append([]string(nil), current[start:]...)
+			tokens = carried
+		}
+		current = append(current, w)
+		tokens += wt
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, strings.Join(current, " "))
+	}
+	return chunks
+}
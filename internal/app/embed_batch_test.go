@@ -0,0 +1,111 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// embedDoer fakes /embeddings: each vector is [len(input)], returned in reverse
+// order. The first failN requests answer 503.
+type embedDoer struct {
+	calls atomic.Int32
+	failN int32
+}
+
+func (d *embedDoer) Do(req *http.Request) (*http.Response, error) {
+	n := d.calls.Add(1)
+	if n <= d.failN {
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader("busy"))}, nil
+	}
+
+	var in EmbeddingRequest
+	if err := json.NewDecoder(req.Body).Decode(&in); err != nil {
+		return nil, err
+	}
+	out := EmbeddingResponse{Model: in.Model}
+	for i := len(in.Input) - 1; i >= 0; i-- {
+		out.Data = append(out.Data, EmbeddingData{Index: i, Embedding: []float64{float64(len(in.Input[i]))}})
+	}
+	out.Usage.TotalTokens = len(in.Input)
+
+	body, _ := json.Marshal(out)
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body))}, nil
+}
+
+func TestEmbedBatchOrderAndRetry(t *testing.T) {
+	doer := &embedDoer{failN: 1}
+	cfg := ClientConfig{
+		APIKey:      "k",
+		RetryConfig: RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+	}
+	c := NewClient(cfg, NewLogger(false), doer)
+
+	texts := []string{"a", "bb", "ccc", "dddd", "eeeee", strings.Repeat("word ", 40)}
+	res, err := c.EmbedBatch(context.Background(), texts, EmbedBatchOptions{
+		Model:          "m",
+		MaxChunkTokens: 20,
+		OverlapTokens:  5,
+		BatchSize:      2,
+		Concurrency:    3,
+	})
+	if err != nil {
+		t.Fatalf("EmbedBatch() error = %v", err)
+	}
+
+	if len(res.Chunks) <= len(texts) {
+		t.Fatalf("expected long input to be split, got %d chunks", len(res.Chunks))
+	}
+	for i, ch := range res.Chunks[:5] {
+		if ch.Input != i || ch.Embedding[0] != float64(len(texts[i])) {
+			t.Fatalf("chunk %d out of order: %+v", i, ch)
+		}
+	}
+	for _, ch := range res.Chunks {
+		if len(ch.Embedding) == 0 {
+			t.Fatalf("missing embedding for input %d chunk %d", ch.Input, ch.Chunk)
+		}
+	}
+	if got := doer.calls.Load(); got != int32(res.Batches)+1 {
+		t.Fatalf("expected %d calls (one retry), got %d", res.Batches+1, got)
+	}
+}
+
+func TestSplitByTokens(t *testing.T) {
+	if got := SplitByTokens("short text", 100, 10); len(got) != 1 {
+		t.Fatalf("expected short text unchanged, got %d chunks", len(got))
+	}
+
+	text := strings.Repeat("abc ", 100)
+	chunks := SplitByTokens(text, 10, 3)
+	if len(chunks) < 10 {
+		t.Fatalf("expected many chunks, got %d", len(chunks))
+	}
+	for _, ch := range chunks {
+		if EstimateTokens(ch) > 10 {
+			t.Fatalf("chunk exceeds token bound: %q", ch)
+		}
+	}
+	if !strings.HasPrefix(chunks[1], "abc abc") {
+		t.Fatalf("expected overlap at chunk start, got %q", chunks[1])
+	}
+
+	// A long word of multi-byte runes is cut between runes.
+	word := strings.Repeat("é", 50) + strings.Repeat("日", 50)
+	pieces := SplitByTokens(word, 7, 0)
+	if strings.Join(pieces, "") != word {
+		t.Fatalf("pieces do not reassemble the word: %q", pieces)
+	}
+	for _, p := range pieces {
+		if !utf8.ValidString(p) {
+			t.Fatalf("piece is not valid UTF-8: %q", p)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Sentinel errors matched by *APIError via errors.Is. ErrCircuitOpen is
+// returned by ResilientDoer while a model is failing.
+var (
+	ErrRateLimited   = errors.New("rate limited")
+	ErrAuth          = errors.New("authentication failed")
+	ErrContextLength = errors.New("context length exceeded")
+	ErrModelNotFound = errors.New("model not found")
+	ErrUnavailable   = errors.New("service unavailable")
+	ErrCircuitOpen   = errors.New("circuit open")
+)
+
+// APIError is a non-2xx API response. Type, Code and Message are parsed from
+// the provider's JSON error body when present.
+type APIError struct {
+	StatusCode int
+	Body       string        // raw response body
+	Type       string        // e.g. "invalid_request_error"
+	Code       string        // e.g. "context_length_exceeded"
+	Message    string        // human-readable message
+	RetryAfter time.Duration // from the Retry-After header (0 = not sent)
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("API error: %d - %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("API error: %d - %s", e.StatusCode, e.Body)
+}
+
+// Is reports whether e belongs to the class named by a sentinel error.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests || e.hasCode("rate_limit")
+	case ErrAuth:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrContextLength:
+		// Phrases alone are too loose: a 429 says "too many tokens per minute".
+		if e.hasCode("context_length") {
+			return true
+		}
+		return (e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusRequestEntityTooLarge) &&
+			e.mentions("context length", "context window", "maximum context", "too many tokens", "prompt is too long")
+	case ErrModelNotFound:
+		return e.hasCode("model_not_found") ||
+			(e.StatusCode == http.StatusNotFound && e.mentions("model"))
+	case ErrUnavailable:
+		switch e.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout, 529:
+			return true
+		}
+	}
+	return false
+}
+
+// hasCode reports whether the error code or type contains s.
+func (e *APIError) hasCode(s string) bool {
+	return strings.Contains(e.Code, s) || strings.Contains(e.Type, s)
+}
+
+// mentions reports whether the message (or raw body) contains any of phrases.
+func (e *APIError) mentions(phrases ...string) bool {
+	text := strings.ToLower(e.Message + " " + e.Body)
+	for _, p := range phrases {
+		if strings.Contains(text, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// newAPIError builds an APIError from a failed response and its body.
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	e := &APIError{
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+	e.Type, e.Code, e.Message = parseErrorBody(body)
+	return e
+}
+
+// parseErrorBody extracts type, code and message from the common error
+// shapes: {"error": {"type", "code", "message"}}, {"error": "msg"},
+// {"message": "msg"} and {"detail": "msg"}.
+func parseErrorBody(body []byte) (typ, code, message string) {
+	var raw struct {
+		Error   json.RawMessage `json:"error"`
+		Message string          `json:"message"`
+		Detail  string          `json:"detail"`
+	}
+	if json.Unmarshal(body, &raw) != nil {
+		return "", "", ""
+	}
+
+	var obj struct {
+		Type    string          `json:"type"`
+		Code    json.RawMessage `json:"code"`
+		Message string          `json:"message"`
+	}
+	var str string
+	switch {
+	case json.Unmarshal(raw.Error, &obj) == nil:
+		typ, message = obj.Type, obj.Message
+		code = strings.Trim(string(obj.Code), `"`) // string or number
+		if code == "null" {
+			code = ""
+		}
+	case json.Unmarshal(raw.Error, &str) == nil:
+		message = str
+	}
+	if message == "" {
+		message = raw.Message
+	}
+	if message == "" {
+		message = raw.Detail
+	}
+	return typ, code, message
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(max(secs, 0)) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// isRetryableError reports whether err is transient: rate limiting, an
+// unavailable upstream, a timeout, or a refused, reset or dropped connection.
+func isRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrUnavailable) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsTemporary {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// retryAfter returns the Retry-After delay carried by err, if any.
+func retryAfter(err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.RetryAfter
+	}
+	return 0
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAPIErrorClassification(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   error
+	}{
+		{429, `{"error":{"type":"rate_limit_error","message":"slow down"}}`, ErrRateLimited},
+		{401, `{"error":"Unauthorized"}`, ErrAuth},
+		{400, `{"error":{"type":"invalid_request_error","code":"context_length_exceeded","message":"This model's maximum context length is 131072 tokens"}}`, ErrContextLength},
+		{400, `{"detail":"Prompt is too long: 200000 tokens > 131072"}`, ErrContextLength},
+		{413, `{"error":"too many tokens in request"}`, ErrContextLength},
+		{404, `{"error":{"message":"The model hf:x/y does not exist","code":"model_not_found"}}`, ErrModelNotFound},
+		{503, `overloaded`, ErrUnavailable},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+		err := fmt.Errorf("wrapped: %w", newAPIError(resp, []byte(tt.body)))
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%d %s: not %v", tt.status, tt.body, tt.want)
+		}
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+			t.Errorf("%d: errors.As failed", tt.status)
+		}
+	}
+
+	e := newAPIError(&http.Response{StatusCode: 400, Header: http.Header{}}, []byte(`{"error":{"message":"bad temperature","code":400}}`))
+	if errors.Is(e, ErrContextLength) || errors.Is(e, ErrRateLimited) || isRetryableError(e) {
+		t.Fatalf("plain 400 misclassified: %+v", e)
+	}
+	if e.Code != "400" || e.Error() != "API error: 400 - bad temperature" {
+		t.Fatalf("parsed %+v, Error() = %q", e, e.Error())
+	}
+
+	e = newAPIError(&http.Response{StatusCode: 429, Header: http.Header{}}, []byte(`{"error":{"message":"Too many tokens per minute, retry later"}}`))
+	if errors.Is(e, ErrContextLength) || !errors.Is(e, ErrRateLimited) {
+		t.Fatalf("token rate limit misclassified: %+v", e)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	if got := parseRetryAfter("7", now); got != 7*time.Second {
+		t.Errorf("seconds = %v", got)
+	}
+	if got := parseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now); got != 30*time.Second {
+		t.Errorf("date = %v", got)
+	}
+	if got := parseRetryAfter("soon", now); got != 0 {
+		t.Errorf("invalid = %v", got)
+	}
+}
+
+// retryAfterDoer answers 429 with Retry-After once, then succeeds.
+type retryAfterDoer struct {
+	calls      atomic.Int32
+	retryAfter string
+}
+
+func (d *retryAfterDoer) Do(req *http.Request) (*http.Response, error) {
+	if d.calls.Add(1) == 1 {
+		h := http.Header{"Retry-After": []string{d.retryAfter}}
+		return &http.Response{StatusCode: 429, Header: h, Body: io.NopCloser(strings.NewReader(`{"error":"rate limited"}`))}, nil
+	}
+	body := `{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`
+	return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}, nil
+}
+
+func TestRetryHonoursRetryAfter(t *testing.T) {
+	cfg := ClientConfig{
+		APIKey:      "k",
+		RetryConfig: RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Second},
+	}
+
+	doer := &retryAfterDoer{retryAfter: "1"}
+	started := time.Now()
+	if _, _, err := NewClient(cfg, NewLogger(false), doer).Chat(context.Background(), "hi", ChatOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(started); elapsed < time.Second {
+		t.Fatalf("retried after %v, want >= 1s", elapsed)
+	}
+
+	// A Retry-After beyond MaxBackoff is not waited for.
+	doer = &retryAfterDoer{retryAfter: "120"}
+	_, _, err := NewClient(cfg, NewLogger(false), doer).Chat(context.Background(), "hi", ChatOptions{})
+	var apiErr *APIError
+	if !errors.Is(err, ErrRateLimited) || !errors.As(err, &apiErr) || apiErr.RetryAfter != 2*time.Minute {
+		t.Fatalf("err = %v", err)
+	}
+	if doer.calls.Load() != 1 {
+		t.Fatalf("calls = %d", doer.calls.Load())
+	}
+}
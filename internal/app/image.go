@@ -0,0 +1,225 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/dotcommander/syn/internal/imgprep"
+)
+
+// supportedImageTypes lists the MIME types accepted by vision models.
+var supportedImageTypes = map[string]bool{ //nolint:gochecknoglobals // read-only lookup table
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// IsImagePath reports whether path has a supported image file extension.
+func IsImagePath(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp":
+		return true
+	default:
+		return false
+	}
+}
+
+// DetectImageMIME returns the MIME type of image data from its magic bytes.
+// Data that is not JPEG, PNG, GIF or WebP is rejected.
+func DetectImageMIME(data []byte) (string, error) {
+	mimeType := http.DetectContentType(data)
+	if !supportedImageTypes[mimeType] {
+		return "", fmt.Errorf("unsupported image data (detected %s; expected JPEG, PNG, GIF or WebP)", mimeType)
+	}
+	return mimeType, nil
+}
+
+// ImageDataURL encodes image bytes as a base64 data: URL.
+func ImageDataURL(data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", fmt.Errorf("image data is empty")
+	}
+	mimeType, err := DetectImageMIME(data)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data)), nil
+}
+
+// ErrImageTooLarge is returned when encoded images exceed ImageConfig.MaxBytes.
+var ErrImageTooLarge = errors.New("image payload too large")
+
+// PrepareImage reads and prepares a local image once, returning a data: URL
+// that later requests send without touching the file again. Remote URLs are
+// returned unchanged.
+func (c *Client) PrepareImage(imageSource string) (string, error) {
+	return c.resolveImageURL(imageSource)
+}
+
+// resolveImageURL converts an image source to a URL for the API. Remote URLs
+// and data: URLs this client already prepared are sent as-is; local files and
+// other data: URLs are prepared and re-encoded.
+func (c *Client) resolveImageURL(imageSource string) (string, error) {
+	if strings.HasPrefix(imageSource, "http://") || strings.HasPrefix(imageSource, "https://") {
+		return imageSource, nil
+	}
+	if c.prepared.has(imageSource) {
+		return imageSource, nil
+	}
+
+	var (
+		data []byte
+		err  error
+		name = imageSource
+	)
+	if rest, ok := strings.CutPrefix(imageSource, "data:"); ok {
+		name = "inline image"
+		_, encoded, found := strings.Cut(rest, ";base64,")
+		if !found {
+			return "", fmt.Errorf("unsupported data URL: only base64 image data is accepted")
+		}
+		if data, err = base64.StdEncoding.DecodeString(encoded); err != nil {
+			return "", fmt.Errorf("invalid data URL: %w", err)
+		}
+	} else if data, err = os.ReadFile(imageSource); err != nil {
+		return "", fmt.Errorf("failed to read image file %s: %w", imageSource, err)
+	}
+
+	url, err := c.prepareImage(data, name)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	c.prepared.add(url)
+	return url, nil
+}
+
+// maxPreparedImages bounds preparedSet; older entries are forgotten first,
+// which only costs a re-encode if an old image is sent again.
+const maxPreparedImages = 256
+
+// preparedSet remembers data: URLs a client prepared by their SHA-256, so
+// multi-megabyte payloads are never kept as map keys.
+type preparedSet struct {
+	mu      sync.Mutex
+	digests map[[sha256.Size]byte]struct{}
+	order   [][sha256.Size]byte
+}
+
+func (s *preparedSet) has(url string) bool {
+	sum := sha256.Sum256([]byte(url))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.digests[sum]
+	return ok
+}
+
+func (s *preparedSet) add(url string) {
+	sum := sha256.Sum256([]byte(url))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.digests == nil {
+		s.digests = make(map[[sha256.Size]byte]struct{})
+	}
+	if _, ok := s.digests[sum]; ok {
+		return
+	}
+	if len(s.order) == maxPreparedImages {
+		delete(s.digests, s.order[0])
+		s.order = s.order[1:]
+	}
+	s.digests[sum] = struct{}{}
+	s.order = append(s.order, sum)
+}
+
+// prepareImage validates image bytes, downscales and re-encodes them per
+// ClientConfig.Image, and returns a data: URL.
+func (c *Client) prepareImage(data []byte, name string) (string, error) {
+	if _, err := DetectImageMIME(data); err != nil {
+		return "", err
+	}
+
+	res, err := imgprep.Prepare(data, c.config.Image.Options)
+	if err != nil {
+		return "", err
+	}
+	c.logger.Debug("prepared image",
+		"source", name,
+		"bytes_in", len(data),
+		"bytes_out", len(res.Data),
+		"width", res.Width,
+		"height", res.Height,
+		"processed", res.Processed)
+
+	return fmt.Sprintf("data:%s;base64,%s", res.MIMEType, base64.StdEncoding.EncodeToString(res.Data)), nil
+}
+
+// checkImagePayload fails before upload when the encoded images in a request
+// exceed ClientConfig.Image.MaxBytes.
+func (c *Client) checkImagePayload(urls []string) error {
+	limit := c.config.Image.MaxBytes
+	if limit <= 0 {
+		return nil
+	}
+	total := 0
+	for _, u := range urls {
+		if strings.HasPrefix(u, "data:") {
+			total += len(u)
+		}
+	}
+	if total > limit {
+		return fmt.Errorf("%w: %.1f MB encoded exceeds the %.1f MB limit (lower vision.max_dimension or vision.quality, or raise vision.max_bytes)",
+			ErrImageTooLarge, float64(total)/(1<<20), float64(limit)/(1<<20))
+	}
+	return nil
+}
+
+// resolveImageParts returns messages with local image references prepared
+// and encoded as data: URLs. Messages that hold images are copied, so callers'
+// history keeps the original paths.
+func (c *Client) resolveImageParts(messages []Message) ([]Message, error) {
+	out := messages
+	copied := false
+	var urls []string
+	for i, m := range messages {
+		if len(m.Images()) == 0 {
+			continue
+		}
+		if !copied {
+			out = append([]Message(nil), messages...)
+			copied = true
+		}
+		parts := make([]ContentPart, len(m.Parts))
+		for j, p := range m.Parts {
+			if p.ImageURL != nil {
+				url, err := c.resolveImageURL(p.ImageURL.URL)
+				if err != nil {
+					return nil, err
+				}
+				p.ImageURL = &ImageURL{URL: url}
+				urls = append(urls, url)
+			}
+			parts[j] = p
+		}
+		out[i].Parts = parts
+	}
+	if err := c.checkImagePayload(urls); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// imageLabel names an image source for multi-image prompts.
+func imageLabel(src string) string {
+	if strings.HasPrefix(src, "data:") {
+		return "inline image"
+	}
+	return filepath.Base(src)
+}
@@ -0,0 +1,147 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dotcommander/syn/internal/ratelimit"
+)
+
+// imageTokens is the prompt-token estimate reserved for each image part.
+const imageTokens = 1000
+
+// rateLimitDoer waits for the request's model to have request and token
+// budget before sending. It reserves the estimated prompt tokens and charges
+// the difference once the response reports its usage.
+type rateLimitDoer struct {
+	next    HTTPDoer
+	limiter *ratelimit.Limiter
+	logger  *slog.Logger
+}
+
+func (d *rateLimitDoer) Do(req *http.Request) (*http.Response, error) {
+	model, reserved := requestBudget(req)
+	waited, err := d.limiter.Wait(req.Context(), model, reserved)
+	if err != nil {
+		if req.Context().Err() != nil {
+			return nil, err
+		}
+		d.logger.Warn("rate limiter unavailable, sending without it", "error", err)
+		markAttempt(req.Context())
+		return d.next.Do(req)
+	}
+	if waited > 0 {
+		d.logger.Debug("waited for rate limit", "model", model, "waited", waited)
+		trace.SpanFromContext(req.Context()).AddEvent("rate_limit.wait",
+			trace.WithAttributes(attribute.Int64("waited_ms", waited.Milliseconds())))
+	}
+
+	markAttempt(req.Context())
+	resp, err := d.next.Do(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	resp.Body = &usageBody{ReadCloser: resp.Body, onUsage: func(u Usage) {
+		if err := d.limiter.Charge(model, u.TotalTokens-reserved); err != nil {
+			d.logger.Debug("failed to charge rate limit", "error", err)
+		}
+	}}
+	return resp, nil
+}
+
+// requestBudget returns the model named in a JSON request body and an
+// estimate of its prompt tokens. Requests without a replayable body use the
+// default limit and reserve no tokens.
+func requestBudget(req *http.Request) (string, int) {
+	if req.GetBody == nil {
+		return "", 0
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return "", 0
+	}
+	defer body.Close()
+
+	var in struct {
+		Model    string          `json:"model"`
+		Messages []Message       `json:"messages"`
+		Input    json.RawMessage `json:"input"` // embeddings: string or []string
+	}
+	if json.NewDecoder(body).Decode(&in) != nil {
+		return "", 0
+	}
+
+	tokens := EstimateTokens(string(in.Input))
+	for _, m := range in.Messages {
+		tokens += EstimateTokens(m.Content)
+		for _, p := range m.Parts {
+			if p.ImageURL != nil {
+				tokens += imageTokens
+			} else {
+				tokens += EstimateTokens(p.Text)
+			}
+		}
+	}
+	return ResolveModel(in.Model), tokens
+}
+
+// usageBody watches a response body for its usage object, calling
+// onUsage once when the body is exhausted or closed. It keeps only the tail
+// of the body, where both JSON responses and the final stream chunk carry
+// usage.
+type usageBody struct {
+	io.ReadCloser
+	onUsage func(Usage)
+	tail    []byte
+	done    bool
+}
+
+const usageTail = 8 << 10
+
+func (b *usageBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.tail = append(b.tail, p[:n]...)
+	if len(b.tail) > 2*usageTail {
+		b.tail = append(b.tail[:0], b.tail[len(b.tail)-usageTail:]...)
+	}
+	if err == io.EOF {
+		b.report()
+	}
+	return n, err
+}
+
+func (b *usageBody) Close() error {
+	b.report()
+	return b.ReadCloser.Close()
+}
+
+func (b *usageBody) report() {
+	if b.done {
+		return
+	}
+	b.done = true
+
+	i := bytes.LastIndex(b.tail, []byte(`"usage"`))
+	if i < 0 {
+		return
+	}
+	rest := bytes.TrimLeft(b.tail[i+len(`"usage"`):], " \t\r\n:")
+	var usage Usage
+	if json.NewDecoder(bytes.NewReader(rest)).Decode(&usage) == nil && usage.TotalTokens > 0 {
+		b.onUsage(usage)
+	}
+}
+
+// withRateLimit wraps next in a rateLimitDoer when limiter is set.
+func withRateLimit(next HTTPDoer, limiter *ratelimit.Limiter, logger *slog.Logger) HTTPDoer {
+	if limiter == nil {
+		return next
+	}
+	return &rateLimitDoer{next: next, limiter: limiter, logger: logger}
+}
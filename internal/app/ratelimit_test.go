@@ -0,0 +1,38 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dotcommander/syn/internal/ratelimit"
+)
+
+func TestRateLimitChargesReportedUsage(t *testing.T) {
+	model := ResolveModel("deepseek")
+	limiter := ratelimit.New(filepath.Join(t.TempDir(), "rl.json"), ratelimit.Limit{},
+		map[string]ratelimit.Limit{model: {TokensPerMinute: 1000}})
+	body := `{"choices":[{"message":{"role":"assistant","content":"ok"}}],"usage":{"prompt_tokens":10,"completion_tokens":890,"total_tokens":900}}`
+	doer := &scriptDoer{script: []func() (*http.Response, error){status(http.StatusOK, body)}}
+	c := NewClient(ClientConfig{APIKey: "k", Model: "deepseek", RateLimiter: limiter}, NewLogger(false), doer)
+
+	if _, _, err := c.Chat(context.Background(), "hello", ChatOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 900 of 1000 tokens are spent, so a ~250-token prompt has to wait.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	prompt := string(make([]byte, 1000))
+	if _, _, err := c.Chat(ctx, prompt, ChatOptions{}); !errors.Is(err, context.DeadlineExceeded) || doer.calls != 1 {
+		t.Fatalf("err = %v after %d calls", err, doer.calls)
+	}
+
+	// Other models are not limited.
+	if _, _, err := c.Chat(context.Background(), prompt, ChatOptions{Model: "glm"}); err != nil || doer.calls != 2 {
+		t.Fatalf("err = %v after %d calls", err, doer.calls)
+	}
+}
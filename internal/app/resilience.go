@@ -0,0 +1,337 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CircuitBreakerConfig configures the circuit breaker, which tracks each
+// host and model pair separately.
+type CircuitBreakerConfig struct {
+	Threshold int           // consecutive failed requests that open the circuit (0 = disabled)
+	Cooldown  time.Duration // how long the circuit stays open before a probe (default: 30s)
+}
+
+type noRetryKey struct{}
+
+// withoutRetry marks ctx so that ResilientDoer sends its requests once,
+// bypassing retries and the circuit breaker.
+func withoutRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+// ResilientDoer decorates an HTTPDoer with retries and a circuit breaker.
+// Transport errors and 429/5xx responses are retried with exponential
+// backoff and jitter, honouring Retry-After. A 200 response whose body fails
+// before any data is read (a stream dropped before its first token) is sent
+// again; once data has been read, errors are returned as is. After
+// Threshold consecutive failed requests to a model on a host, requests for
+// that model fail fast with ErrCircuitOpen until Cooldown has passed and a
+// probe request succeeds; other models on the host are unaffected.
+type ResilientDoer struct {
+	next    HTTPDoer
+	retry   RetryConfig
+	breaker *circuitBreaker
+	logger  *slog.Logger
+}
+
+// NewResilientDoer wraps next with retry and circuit-breaker behaviour.
+func NewResilientDoer(next HTTPDoer, retry RetryConfig, breaker CircuitBreakerConfig, logger *slog.Logger) *ResilientDoer {
+	if retry.MaxAttempts < 1 {
+		retry.MaxAttempts = 1
+	}
+	if retry.InitialBackoff < 1 {
+		retry.InitialBackoff = 1 * time.Second
+	}
+	if retry.MaxBackoff < 1 {
+		retry.MaxBackoff = 30 * time.Second
+	}
+	return &ResilientDoer{
+		next:    next,
+		retry:   retry,
+		breaker: newCircuitBreaker(breaker),
+		logger:  logger,
+	}
+}
+
+// Do sends req, retrying transient failures.
+func (d *ResilientDoer) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if ctx.Value(noRetryKey{}) != nil {
+		return d.next.Do(req)
+	}
+
+	circuit := circuitKey(req)
+	if err := d.breaker.allow(circuit); err != nil {
+		return nil, err
+	}
+
+	resp, attempts, err := d.send(req, 1, nil)
+	if attempts > 1 {
+		trace.SpanFromContext(ctx).SetAttributes(attrResendCount.Int(attempts - 1))
+	}
+	if ctx.Err() != nil {
+		d.breaker.release(circuit)
+	} else {
+		d.breaker.record(circuit, err != nil || resp.StatusCode >= http.StatusInternalServerError)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		resp.Body = &retryBody{doer: d, req: req, attempt: attempts, body: resp.Body}
+	}
+	return resp, nil
+}
+
+// send performs req starting at attempt, after lastErr when attempt > 1. It
+// returns the final response or error and the number of the last attempt.
+// A retryable response on the last attempt is returned unread so the caller
+// can parse the error body.
+func (d *ResilientDoer) send(req *http.Request, attempt int, lastErr error) (*http.Response, int, error) {
+	ctx := req.Context()
+	first := attempt
+
+	for ; ; attempt++ {
+		if attempt > 1 {
+			backoff := calculateBackoff(attempt, d.retry.InitialBackoff, d.retry.MaxBackoff)
+			if wait := retryAfter(lastErr); wait > 0 {
+				backoff = wait
+			}
+			d.logger.Debug("retrying request",
+				"url", req.URL.String(),
+				"attempt", attempt,
+				"max_attempts", d.retry.MaxAttempts,
+				"backoff", backoff,
+				"error", lastErr)
+			trace.SpanFromContext(ctx).AddEvent("retry", trace.WithAttributes(
+				attribute.Int("attempt", attempt),
+				attribute.Int64("backoff_ms", backoff.Milliseconds()),
+				attribute.String("error", lastErr.Error())))
+
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return nil, attempt - 1, ctx.Err()
+			}
+		}
+
+		r, err := rewind(req, attempt)
+		if err != nil {
+			return nil, attempt, err
+		}
+		markAttempt(ctx)
+		resp, err := d.next.Do(r)
+
+		if err == nil && !retryableStatus(resp.StatusCode) {
+			return resp, attempt, nil
+		}
+		if err == nil {
+			lastErr = newAPIError(resp, nil) // status and Retry-After only
+		} else {
+			lastErr = err
+		}
+
+		if d.stop(ctx, req, attempt, lastErr) {
+			if err == nil {
+				return resp, attempt, nil
+			}
+			if attempt > first {
+				return nil, attempt, fmt.Errorf("request failed after %d attempts: %w", attempt, err)
+			}
+			return nil, attempt, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+	}
+}
+
+// stop reports whether the failed attempt should not be retried.
+func (d *ResilientDoer) stop(ctx context.Context, req *http.Request, attempt int, err error) bool {
+	switch {
+	case attempt >= d.retry.MaxAttempts || ctx.Err() != nil || !isRetryableError(err):
+		return true
+	case req.Body != nil && req.Body != http.NoBody && req.GetBody == nil:
+		d.logger.Debug("not retrying: request body cannot be replayed", "url", req.URL.String())
+		return true
+	case retryAfter(err) > d.retry.MaxBackoff:
+		d.logger.Debug("not retrying: Retry-After exceeds max backoff", "retry_after", retryAfter(err), "max_backoff", d.retry.MaxBackoff)
+		return true
+	}
+	return false
+}
+
+// rewind returns req for the first attempt and a copy with a fresh body for
+// later ones.
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 1 {
+		return req, nil
+	}
+	r := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to replay request body: %w", err)
+		}
+		r.Body = body
+	}
+	return r, nil
+}
+
+// retryableStatus reports whether an HTTP status is worth retrying.
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout, 529:
+		return true
+	}
+	return false
+}
+
+// retryBody re-sends the request when the response body fails before any
+// data has been read from it.
+type retryBody struct {
+	doer    *ResilientDoer
+	req     *http.Request
+	attempt int
+	body    io.ReadCloser
+	read    bool
+}
+
+func (b *retryBody) Read(p []byte) (int, error) {
+	for {
+		n, err := b.body.Read(p)
+		if n > 0 {
+			b.read = true
+		}
+		if err == nil || err == io.EOF || b.read || b.doer.stop(b.req.Context(), b.req, b.attempt, err) {
+			return n, err
+		}
+
+		b.body.Close()
+		resp, attempt, serr := b.doer.send(b.req, b.attempt+1, err)
+		b.attempt = attempt
+		if serr != nil {
+			b.body = http.NoBody
+			return 0, serr
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			b.body = http.NoBody
+			return 0, newAPIError(resp, body)
+		}
+		b.body = resp.Body
+	}
+}
+
+func (b *retryBody) Close() error {
+	return b.body.Close()
+}
+
+// circuitKey names the circuit req belongs to: its host, plus the model
+// named in the request body when there is one.
+func circuitKey(req *http.Request) string {
+	if model, _ := requestModel(req); model != "" {
+		return fmt.Sprintf("%s (%s)", req.URL.Host, model)
+	}
+	return req.URL.Host
+}
+
+// circuitBreaker tracks consecutive failures per circuit (see circuitKey).
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	circuits map[string]*breakerState
+}
+
+type breakerState struct {
+	failures  int
+	openUntil time.Time
+	probing   bool // a half-open probe request is in flight
+}
+
+// newCircuitBreaker returns nil (no breaker) when the threshold is not positive.
+func newCircuitBreaker(cfg CircuitBreakerConfig) *circuitBreaker {
+	if cfg.Threshold <= 0 {
+		return nil
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 30 * time.Second
+	}
+	return &circuitBreaker{
+		threshold: cfg.Threshold,
+		cooldown:  cfg.Cooldown,
+		now:       time.Now,
+		circuits:  make(map[string]*breakerState),
+	}
+}
+
+// allow returns ErrCircuitOpen while circuit is open. After the cooldown it
+// lets a single probe request through.
+func (b *circuitBreaker) allow(circuit string) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := b.circuits[circuit]
+	if s == nil || s.failures < b.threshold {
+		return nil
+	}
+	if wait := s.openUntil.Sub(b.now()); wait > 0 || s.probing {
+		return fmt.Errorf("%w: %s failed %d requests in a row; retrying in %s",
+			ErrCircuitOpen, circuit, s.failures, max(wait, 0).Round(time.Second))
+	}
+	s.probing = true
+	return nil
+}
+
+// record counts a finished request, opening the circuit at the threshold.
+func (b *circuitBreaker) record(circuit string, failed bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := b.circuits[circuit]
+	if s == nil {
+		s = &breakerState{}
+		b.circuits[circuit] = s
+	}
+	s.probing = false
+	if !failed {
+		s.failures = 0
+		return
+	}
+	s.failures++
+	if s.failures >= b.threshold {
+		s.openUntil = b.now().Add(b.cooldown)
+	}
+}
+
+// release ends a request that was cancelled without counting it.
+func (b *circuitBreaker) release(circuit string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if s := b.circuits[circuit]; s != nil {
+		s.probing = false
+	}
+}
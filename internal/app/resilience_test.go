@@ -0,0 +1,151 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// scriptDoer answers each request with the next scripted response, repeating
+// the last one.
+type scriptDoer struct {
+	script []func() (*http.Response, error)
+	calls  int
+}
+
+func (d *scriptDoer) Do(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_, _ = io.Copy(io.Discard, req.Body)
+	}
+	step := d.script[min(d.calls, len(d.script)-1)]
+	d.calls++
+	return step()
+}
+
+func status(code int, body string) func() (*http.Response, error) {
+	return func() (*http.Response, error) {
+		return &http.Response{StatusCode: code, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}, nil
+	}
+}
+
+// brokenStream answers 200 with body data followed by a reset connection.
+func brokenStream(data string) func() (*http.Response, error) {
+	return func() (*http.Response, error) {
+		body := io.MultiReader(strings.NewReader(data), errReader{syscall.ECONNRESET})
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(body)}, nil
+	}
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
+
+var fastRetry = RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond} //nolint:gochecknoglobals // test fixture
+
+func TestResilientDoerRetriesEveryEndpoint(t *testing.T) {
+	doer := &scriptDoer{script: []func() (*http.Response, error){
+		func() (*http.Response, error) { return nil, syscall.ECONNREFUSED },
+		status(http.StatusBadGateway, "bad gateway"),
+		status(http.StatusOK, `{"data":[{"id":"m"}]}`),
+	}}
+	c := NewClient(ClientConfig{APIKey: "k", RetryConfig: fastRetry}, NewLogger(false), doer)
+
+	models, err := c.ListModels(context.Background())
+	if err != nil || len(models) != 1 || doer.calls != 3 {
+		t.Fatalf("ListModels = %v, %v after %d calls", models, err, doer.calls)
+	}
+
+	// Non-retryable statuses are returned at once.
+	doer = &scriptDoer{script: []func() (*http.Response, error){status(http.StatusBadRequest, `{"error":"bad"}`)}}
+	c = NewClient(ClientConfig{APIKey: "k", RetryConfig: fastRetry}, NewLogger(false), doer)
+	if _, err := c.Search(context.Background(), "q"); err == nil || doer.calls != 1 {
+		t.Fatalf("Search = %v after %d calls", err, doer.calls)
+	}
+}
+
+func TestResilientDoerRetriesStreamBeforeFirstToken(t *testing.T) {
+	ok := "data: {\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\ndata: [DONE]\n\n"
+	doer := &scriptDoer{script: []func() (*http.Response, error){brokenStream(""), status(http.StatusOK, ok)}}
+	c := NewClient(ClientConfig{APIKey: "k", RetryConfig: fastRetry}, NewLogger(false), doer)
+
+	res, err := c.ChatStream(context.Background(), "hi", ChatOptions{})
+	if err != nil || res.Content != "hi" || doer.calls != 2 {
+		t.Fatalf("ChatStream = %+v, %v after %d calls", res, err, doer.calls)
+	}
+
+	// Once tokens have arrived, a dropped stream is not replayed.
+	doer = &scriptDoer{script: []func() (*http.Response, error){
+		brokenStream("data: {\"choices\":[{\"delta\":{\"content\":\"par\"}}]}\n\n"),
+		status(http.StatusOK, ok),
+	}}
+	c = NewClient(ClientConfig{APIKey: "k", RetryConfig: fastRetry}, NewLogger(false), doer)
+	if _, err := c.ChatStream(context.Background(), "hi", ChatOptions{}); !errors.Is(err, syscall.ECONNRESET) || doer.calls != 1 {
+		t.Fatalf("ChatStream err = %v after %d calls", err, doer.calls)
+	}
+}
+
+func TestStreamTimingExcludesBackoff(t *testing.T) {
+	ok := "data: {\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\ndata: [DONE]\n\n"
+	doer := &scriptDoer{script: []func() (*http.Response, error){status(http.StatusServiceUnavailable, "busy"), status(http.StatusOK, ok)}}
+	backoff := 300 * time.Millisecond
+	c := NewClient(ClientConfig{APIKey: "k", RetryConfig: RetryConfig{MaxAttempts: 2, InitialBackoff: backoff, MaxBackoff: backoff}}, NewLogger(false), doer)
+
+	res, err := c.ChatStream(context.Background(), "hi", ChatOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.TTFMS >= backoff.Milliseconds()/2 {
+		t.Errorf("TTFMS = %d, includes the %v backoff", res.TTFMS, backoff)
+	}
+	if res.WaitMS < backoff.Milliseconds()/2 {
+		t.Errorf("WaitMS = %d, want the backoff reported", res.WaitMS)
+	}
+}
+
+func TestCircuitBreakerFailsFast(t *testing.T) {
+	doer := &scriptDoer{script: []func() (*http.Response, error){status(http.StatusServiceUnavailable, "down")}}
+	d := NewResilientDoer(doer, RetryConfig{MaxAttempts: 1}, CircuitBreakerConfig{Threshold: 2, Cooldown: time.Minute}, NewLogger(false))
+	now := time.Now()
+	d.breaker.now = func() time.Time { return now }
+
+	get := func(ctx context.Context) error {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.example.com/models", nil)
+		resp, err := d.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	for range 2 {
+		if err := get(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := get(context.Background()); !errors.Is(err, ErrCircuitOpen) || doer.calls != 2 {
+		t.Fatalf("err = %v after %d calls", err, doer.calls)
+	}
+
+	// NoRetry requests bypass the breaker.
+	if err := get(withoutRetry(context.Background())); err != nil || doer.calls != 3 {
+		t.Fatalf("bypass err = %v after %d calls", err, doer.calls)
+	}
+
+	// After the cooldown one probe goes through; its success closes the circuit.
+	now = now.Add(time.Minute)
+	doer.script = []func() (*http.Response, error){status(http.StatusOK, "{}")}
+	doer.calls = 0
+	for range 2 {
+		if err := get(context.Background()); err != nil {
+			t.Fatalf("after cooldown: %v", err)
+		}
+	}
+	if doer.calls != 2 {
+		t.Fatalf("calls = %d", doer.calls)
+	}
+}
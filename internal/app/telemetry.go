@@ -0,0 +1,244 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies syn's spans and metrics.
+const instrumentationName = "github.com/dotcommander/syn/internal/app"
+
+// Span and metric attribute keys, following the OpenTelemetry GenAI and HTTP
+// semantic conventions where they exist.
+const (
+	attrOperation    = attribute.Key("gen_ai.operation.name")
+	attrProvider     = attribute.Key("gen_ai.provider.name")
+	attrModel        = attribute.Key("gen_ai.request.model")
+	attrInputTokens  = attribute.Key("gen_ai.usage.input_tokens")
+	attrOutputTokens = attribute.Key("gen_ai.usage.output_tokens")
+	attrTokenType    = attribute.Key("gen_ai.token.type")
+	attrMethod       = attribute.Key("http.request.method")
+	attrStatus       = attribute.Key("http.response.status_code")
+	attrResendCount  = attribute.Key("http.request.resend_count")
+	attrServer       = attribute.Key("server.address")
+	attrPath         = attribute.Key("url.path")
+	attrErrorType    = attribute.Key("error.type")
+	attrStream       = attribute.Key("syn.stream")
+	attrTTFT         = attribute.Key("syn.time_to_first_token_ms")
+	attrWait         = attribute.Key("syn.wait_ms")
+)
+
+// telemetryDoer wraps each logical request, including its retries and
+// rate-limit waits, in a client span and records latency, time to first
+// byte and token metrics. Latency and time to first byte are measured from
+// the final attempt; time spent before it is reported separately as wait.
+// With the default global providers it is a no-op.
+type telemetryDoer struct {
+	next       HTTPDoer
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	duration   metric.Float64Histogram
+	wait       metric.Float64Histogram
+	ttft       metric.Float64Histogram
+	tokens     metric.Int64Counter
+}
+
+// newTelemetryDoer wraps next using the given providers, or the global ones
+// when nil.
+func newTelemetryDoer(next HTTPDoer, tp trace.TracerProvider, mp metric.MeterProvider) *telemetryDoer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	if mp == nil {
+		mp = otel.GetMeterProvider()
+	}
+	meter := mp.Meter(instrumentationName)
+	d := &telemetryDoer{
+		next:       next,
+		tracer:     tp.Tracer(instrumentationName),
+		propagator: otel.GetTextMapPropagator(),
+	}
+	// Instrument errors only occur for invalid names; the returned no-op
+	// instruments are safe to use.
+	d.duration, _ = meter.Float64Histogram("syn.client.request.duration",
+		metric.WithUnit("s"), metric.WithDescription("Duration of the final attempt of API requests"))
+	d.wait, _ = meter.Float64Histogram("syn.client.request.wait",
+		metric.WithUnit("s"), metric.WithDescription("Time spent in retry backoff, failed attempts and rate-limit waits before the final attempt"))
+	d.ttft, _ = meter.Float64Histogram("syn.client.time_to_first_token",
+		metric.WithUnit("s"), metric.WithDescription("Time until the first response byte of streamed chat requests"))
+	d.tokens, _ = meter.Int64Counter("syn.client.token.usage",
+		metric.WithUnit("{token}"), metric.WithDescription("Tokens reported by API responses"))
+	return d
+}
+
+func (d *telemetryDoer) Do(req *http.Request) (*http.Response, error) {
+	ctx, clock := withAttemptClock(req.Context())
+	model, stream := requestModel(req)
+	op := operationName(req.URL.Path)
+
+	common := []attribute.KeyValue{
+		attrOperation.String(op),
+		attrProvider.String("synthetic"),
+		attrServer.String(req.URL.Hostname()),
+	}
+	if model != "" {
+		common = append(common, attrModel.String(model))
+	}
+	common = slices.Clip(common) // appends below must not share its array
+	name := op
+	if model != "" {
+		name += " " + model
+	}
+	ctx, span := d.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(common...),
+		trace.WithAttributes(attrMethod.String(req.Method), attrPath.String(req.URL.Path), attrStream.Bool(stream)))
+
+	req = req.WithContext(ctx)
+	d.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := d.next.Do(req)
+	if err != nil {
+		d.finish(ctx, span, clock, common, 0, err)
+		return nil, err
+	}
+	span.SetAttributes(attrStatus.Int(resp.StatusCode))
+	if resp.StatusCode != http.StatusOK {
+		d.finish(ctx, span, clock, common, resp.StatusCode, nil)
+		return resp, nil
+	}
+
+	usage := &usageBody{ReadCloser: resp.Body, onUsage: func(u Usage) {
+		span.SetAttributes(attrInputTokens.Int(u.PromptTokens), attrOutputTokens.Int(u.CompletionTokens))
+		d.tokens.Add(ctx, int64(u.PromptTokens), metric.WithAttributes(append(common, attrTokenType.String("input"))...))
+		d.tokens.Add(ctx, int64(u.CompletionTokens), metric.WithAttributes(append(common, attrTokenType.String("output"))...))
+	}}
+	resp.Body = &telemetryBody{ReadCloser: usage, clock: clock, onDone: func(ttft time.Duration, readErr error) {
+		if stream && ttft > 0 {
+			span.SetAttributes(attrTTFT.Int64(ttft.Milliseconds()))
+			d.ttft.Record(ctx, ttft.Seconds(), metric.WithAttributes(common...))
+		}
+		d.finish(ctx, span, clock, common, resp.StatusCode, readErr)
+	}}
+	return resp, nil
+}
+
+// finish ends span with its status and records the request duration.
+func (d *telemetryDoer) finish(ctx context.Context, span trace.Span, clock *attemptClock, common []attribute.KeyValue, status int, err error) {
+	attrs := slices.Clone(common)
+	if status > 0 {
+		attrs = append(attrs, attrStatus.Int(status))
+	}
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		attrs = append(attrs, attrErrorType.String(errorType(err)))
+	case status >= http.StatusBadRequest:
+		span.SetStatus(codes.Error, http.StatusText(status))
+		attrs = append(attrs, attrErrorType.String(strconv.Itoa(status)))
+	}
+	wait := clock.Wait()
+	span.SetAttributes(attrWait.Int64(wait.Milliseconds()))
+	d.wait.Record(ctx, wait.Seconds(), metric.WithAttributes(attrs...))
+	d.duration.Record(ctx, time.Since(clock.Start()).Seconds(), metric.WithAttributes(attrs...))
+	span.End()
+}
+
+// telemetryBody calls onDone once, when the body is exhausted, fails or is
+// closed, with the time from the final attempt's start to the first byte read.
+type telemetryBody struct {
+	io.ReadCloser
+	clock  *attemptClock
+	onDone func(ttft time.Duration, err error)
+	ttft   time.Duration
+	once   sync.Once
+}
+
+func (b *telemetryBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 && b.ttft == 0 {
+		b.ttft = time.Since(b.clock.Start())
+	}
+	switch {
+	case err == io.EOF:
+		b.done(nil)
+	case err != nil:
+		b.done(err)
+	}
+	return n, err
+}
+
+func (b *telemetryBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.done(nil)
+	return err
+}
+
+func (b *telemetryBody) done(err error) {
+	b.once.Do(func() { b.onDone(b.ttft, err) })
+}
+
+// requestModel returns the model and stream flag of a JSON request body.
+func requestModel(req *http.Request) (string, bool) {
+	if req.GetBody == nil {
+		return "", false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return "", false
+	}
+	defer body.Close()
+
+	var in struct {
+		Model  string `json:"model"`
+		Stream bool   `json:"stream"`
+	}
+	if json.NewDecoder(body).Decode(&in) != nil {
+		return "", false
+	}
+	return in.Model, in.Stream
+}
+
+// operationName maps an endpoint path to a GenAI operation name.
+func operationName(urlPath string) string {
+	switch {
+	case strings.HasSuffix(urlPath, "/chat/completions"):
+		return "chat"
+	case strings.HasSuffix(urlPath, "/embeddings"):
+		return "embeddings"
+	}
+	return path.Base(urlPath)
+}
+
+// errorType names err for the error.type attribute.
+func errorType(err error) string {
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &apiErr):
+		return strconv.Itoa(apiErr.StatusCode)
+	case errors.Is(err, ErrCircuitOpen):
+		return "circuit_open"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	}
+	return "transport"
+}
@@ -0,0 +1,151 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// telemetryClient returns a client recording to in-memory providers.
+func telemetryClient(t *testing.T, doer HTTPDoer) (*Client, *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	t.Helper()
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	cfg := ClientConfig{
+		APIKey:         "k",
+		Model:          "deepseek",
+		RetryConfig:    fastRetry,
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)),
+		MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	}
+	return NewClient(cfg, NewLogger(false), doer), spans, reader
+}
+
+func spanAttr(s sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range s.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+// metricSum totals a counter or histogram's data points, optionally only
+// those carrying match.
+func metricSum(t *testing.T, reader *sdkmetric.ManualReader, name string, match ...attribute.KeyValue) float64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	var sum float64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					if hasAll(dp.Attributes, match) {
+						sum += float64(dp.Value)
+					}
+				}
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					if hasAll(dp.Attributes, match) {
+						sum += float64(dp.Count)
+					}
+				}
+			}
+		}
+	}
+	return sum
+}
+
+func hasAll(set attribute.Set, match []attribute.KeyValue) bool {
+	for _, kv := range match {
+		if v, ok := set.Value(kv.Key); !ok || v != kv.Value {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTelemetryChatSpanAndMetrics(t *testing.T) {
+	body := `{"choices":[{"message":{"role":"assistant","content":"ok"}}],"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7}}`
+	doer := &scriptDoer{script: []func() (*http.Response, error){status(http.StatusServiceUnavailable, ""), status(http.StatusOK, body)}}
+	c, spans, reader := telemetryClient(t, doer)
+
+	if _, _, err := c.Chat(context.Background(), "hi", ChatOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("got %d spans, want 1 covering both attempts", len(ended))
+	}
+	s := ended[0]
+	model := ResolveModel("deepseek")
+	if s.Name() != "chat "+model {
+		t.Fatalf("span name = %q", s.Name())
+	}
+	for key, want := range map[attribute.Key]int64{
+		attrInputTokens:  5,
+		attrOutputTokens: 2,
+		attrResendCount:  1,
+		attrStatus:       http.StatusOK,
+	} {
+		if got := spanAttr(s, key).AsInt64(); got != want {
+			t.Errorf("%s = %d, want %d", key, got, want)
+		}
+	}
+	if len(s.Events()) != 1 || s.Events()[0].Name != "retry" {
+		t.Errorf("events = %+v, want one retry", s.Events())
+	}
+
+	if n := metricSum(t, reader, "syn.client.request.duration", attrModel.String(model)); n != 1 {
+		t.Errorf("duration count = %v, want 1", n)
+	}
+	if n := metricSum(t, reader, "syn.client.token.usage", attrTokenType.String("output")); n != 2 {
+		t.Errorf("output tokens = %v, want 2", n)
+	}
+}
+
+func TestTelemetryStreamTTFTAndErrors(t *testing.T) {
+	sse := "data: {\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\n" +
+		"data: {\"choices\":[],\"usage\":{\"prompt_tokens\":3,\"completion_tokens\":1,\"total_tokens\":4}}\n\ndata: [DONE]\n\n"
+	doer := &scriptDoer{script: []func() (*http.Response, error){status(http.StatusOK, sse), status(http.StatusUnauthorized, `{"error":"bad key"}`)}}
+	c, spans, reader := telemetryClient(t, doer)
+
+	if _, err := c.ChatStream(context.Background(), "hi", ChatOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.Chat(context.Background(), "hi", ChatOptions{}); err == nil {
+		t.Fatal("expected auth error")
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("got %d spans, want 2", len(ended))
+	}
+	if !spanAttr(ended[0], attrStream).AsBool() || spanAttr(ended[0], attrInputTokens).AsInt64() != 3 {
+		t.Errorf("stream span attributes = %v", ended[0].Attributes())
+	}
+	if ended[1].Status().Code != codes.Error {
+		t.Errorf("401 span status = %+v", ended[1].Status())
+	}
+	if n := metricSum(t, reader, "syn.client.time_to_first_token"); n != 1 {
+		t.Errorf("ttft count = %v, want 1", n)
+	}
+	if n := metricSum(t, reader, "syn.client.request.duration", attrErrorType.String("401")); n != 1 {
+		t.Errorf("failed request count = %v, want 1", n)
+	}
+}
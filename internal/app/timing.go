@@ -0,0 +1,52 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type attemptClockKey struct{}
+
+// attemptClock records when the final HTTP attempt of a request started, so
+// latency and TTFT exclude retry backoff and rate-limit waits spent in the
+// doer chain. ResilientDoer and the rate-limit doer mark it right before
+// each call to the doer they wrap; the innermost mark wins.
+type attemptClock struct {
+	created time.Time
+
+	mu    sync.Mutex
+	start time.Time
+}
+
+// withAttemptClock returns ctx carrying a clock, reusing one ctx already has.
+func withAttemptClock(ctx context.Context) (context.Context, *attemptClock) {
+	if c, ok := ctx.Value(attemptClockKey{}).(*attemptClock); ok {
+		return ctx, c
+	}
+	now := time.Now()
+	c := &attemptClock{created: now, start: now}
+	return context.WithValue(ctx, attemptClockKey{}, c), c
+}
+
+// markAttempt records that an attempt is starting now, if ctx has a clock.
+func markAttempt(ctx context.Context) {
+	if c, ok := ctx.Value(attemptClockKey{}).(*attemptClock); ok {
+		c.mu.Lock()
+		c.start = time.Now()
+		c.mu.Unlock()
+	}
+}
+
+// Start returns when the latest attempt started.
+func (c *attemptClock) Start() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.start
+}
+
+// Wait returns the time spent before the latest attempt: retry backoff,
+// rate-limit waits and failed attempts.
+func (c *attemptClock) Wait() time.Duration {
+	return c.Start().Sub(c.created)
+}
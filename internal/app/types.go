@@ -1,9 +1,21 @@
 package app
 
 import (
+	"encoding/json"
 	"fmt"
 	"maps"
+	"slices"
+	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dotcommander/syn/internal/cache"
+	"github.com/dotcommander/syn/internal/catalog"
+	"github.com/dotcommander/syn/internal/imgprep"
+	"github.com/dotcommander/syn/internal/ratelimit"
+	"github.com/dotcommander/syn/internal/textfile"
 )
 
 // ClientConfig holds all configuration for the Synthetic client.
@@ -16,6 +28,34 @@ type ClientConfig struct {
 	Timeout        time.Duration
 	Verbose        bool
 	RetryConfig    RetryConfig
+	CircuitBreaker CircuitBreakerConfig
+	Image          ImageConfig
+	Files          FileConfig
+	VisionModel    string               // model for image requests ("" = choose from Catalog)
+	Catalog        *catalog.Catalog     // model capabilities (nil = built-in table)
+	Fallbacks      map[string][]string  // alias or model ID → models to try when it is unavailable
+	RateLimiter    *ratelimit.Limiter   // shared per-model request/token budget (nil = unlimited)
+	Cache          *cache.Cache         // chat and embedding responses (nil = not cached)
+	SearchCache    *cache.Cache         // search responses (nil = not cached)
+	CacheMode      CacheMode            // how both caches are used
+	Profile        string               // active config profile; scopes cache keys with BaseURL
+	TracerProvider trace.TracerProvider // request spans (nil = otel global)
+	MeterProvider  metric.MeterProvider // request metrics (nil = otel global)
+}
+
+// FileConfig controls how -f files are read into prompts. The zero value
+// reads files in full, rejects binary files and never warns.
+type FileConfig struct {
+	textfile.Options
+	WarnTokens int // warn when a file's estimated tokens exceed this (0 = never)
+}
+
+// ImageConfig controls how local and inline images are prepared for upload.
+// The zero value re-encodes to JPEG at quality 85 without downscaling and
+// applies no size limit.
+type ImageConfig struct {
+	imgprep.Options
+	MaxBytes int // maximum total encoded image payload per request (0 = unlimited)
 }
 
 // RetryConfig configures retry behavior for transient failures.
@@ -25,10 +65,92 @@ type RetryConfig struct {
 	MaxBackoff     time.Duration // Maximum backoff duration (default: 30s)
 }
 
-// Message represents a chat message.
+// Message represents a chat message. When Parts is set the message is
+// multimodal: Parts is sent as the content array and Content keeps the text
+// for display. Image parts may hold local paths; they are encoded at send time.
 type Message struct {
-	Role    string `json:"role"` // "user", "assistant", "system"
-	Content string `json:"content"`
+	Role    string        `json:"role"` // "user", "assistant", "system"
+	Content string        `json:"content"`
+	Parts   []ContentPart `json:"-"`
+}
+
+// ContentPart is one element of a multimodal message.
+type ContentPart struct {
+	Type     string    `json:"type"` // "text" or "image_url"
+	Text     string    `json:"text,omitempty"`
+	ImageURL *ImageURL `json:"image_url,omitempty"`
+}
+
+// ImageURL references an image by URL, data: URL, or local path.
+type ImageURL struct {
+	URL string `json:"url"`
+}
+
+// TextPart returns a text content part.
+func TextPart(text string) ContentPart {
+	return ContentPart{Type: "text", Text: text}
+}
+
+// ImagePart returns an image content part for a URL or local path.
+func ImagePart(source string) ContentPart {
+	return ContentPart{Type: "image_url", ImageURL: &ImageURL{URL: source}}
+}
+
+// Images returns the image references in m, in order.
+func (m Message) Images() []string {
+	var images []string
+	for _, p := range m.Parts {
+		if p.ImageURL != nil {
+			images = append(images, p.ImageURL.URL)
+		}
+	}
+	return images
+}
+
+// MarshalJSON encodes content as a string, or as a part array when Parts is set.
+func (m Message) MarshalJSON() ([]byte, error) {
+	if len(m.Parts) == 0 {
+		return json.Marshal(struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		}{m.Role, m.Content})
+	}
+	return json.Marshal(struct {
+		Role    string        `json:"role"`
+		Content []ContentPart `json:"content"`
+	}{m.Role, m.Parts})
+}
+
+// UnmarshalJSON accepts content as a string or as a part array.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*m = Message{Role: raw.Role}
+
+	content := strings.TrimSpace(string(raw.Content))
+	switch {
+	case content == "" || content == "null":
+		return nil
+	case content[0] == '[':
+		if err := json.Unmarshal(raw.Content, &m.Parts); err != nil {
+			return err
+		}
+		var texts []string
+		for _, p := range m.Parts {
+			if p.Type == "text" {
+				texts = append(texts, p.Text)
+			}
+		}
+		m.Content = strings.Join(texts, "\n")
+		return nil
+	default:
+		return json.Unmarshal(raw.Content, &m.Content)
+	}
 }
 
 // ChatRequest represents the /chat/completions API request.
@@ -70,7 +192,8 @@ type StreamDelta struct {
 type StreamResult struct {
 	Content string
 	Usage   Usage
-	TTFMS   int64 // time to first token in milliseconds
+	TTFMS   int64 // time to first token in milliseconds, from the final attempt
+	WaitMS  int64 // retry backoff and rate-limit waits before the final attempt
 }
 
 // ChatResponse represents the /chat/completions API response.
@@ -92,9 +215,12 @@ type Choice struct {
 
 // Usage represents token usage statistics.
 type Usage struct {
-	PromptTokens     int `json:"prompt_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	TotalTokens      int `json:"total_tokens"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+	TotalTokens      int    `json:"total_tokens"`
+	Model            string `json:"-"` // model that produced the response, after any fallback
+	FallbackFrom     string `json:"-"` // requested model, when a fallback produced the response
+	Cached           bool   `json:"-"` // served from the response cache
 }
 
 // ModelsResponse represents the /models API response.
@@ -103,12 +229,65 @@ type ModelsResponse struct {
 	Data   []Model `json:"data"`
 }
 
-// Model represents a single model.
+// Model represents a single model. Capability fields are optional and only
+// filled when the provider reports them.
 type Model struct {
-	ID      string `json:"id"`
-	Object  string `json:"object"`
-	Created int64  `json:"created"`
-	OwnedBy string `json:"owned_by"`
+	ID                string        `json:"id"`
+	Object            string        `json:"object"`
+	Created           int64         `json:"created"`
+	OwnedBy           string        `json:"owned_by"`
+	Name              string        `json:"name,omitempty"`
+	ContextLength     int           `json:"context_length,omitempty"`
+	MaxOutputLength   int           `json:"max_output_length,omitempty"`
+	InputModalities   []string      `json:"input_modalities,omitempty"`
+	OutputModalities  []string      `json:"output_modalities,omitempty"`
+	SupportedFeatures []string      `json:"supported_features,omitempty"` // e.g. "tools", "json_mode"
+	Pricing           *ModelPricing `json:"pricing,omitempty"`
+}
+
+// ModelPricing holds prices as reported by the provider.
+type ModelPricing struct {
+	Prompt     PriceString `json:"prompt"`
+	Completion PriceString `json:"completion"`
+}
+
+// PriceString accepts a JSON string or number.
+type PriceString string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *PriceString) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*p = PriceString(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("invalid price %s", data)
+	}
+	*p = PriceString(n.String())
+	return nil
+}
+
+// Capabilities converts API-reported metadata to a catalog entry.
+func (m Model) Capabilities() catalog.Model {
+	cm := catalog.Model{
+		ID:               m.ID,
+		Name:             m.Name,
+		ContextLength:    m.ContextLength,
+		MaxOutput:        m.MaxOutputLength,
+		InputModalities:  m.InputModalities,
+		OutputModalities: m.OutputModalities,
+	}
+	if len(m.SupportedFeatures) > 0 {
+		tools := slices.Contains(m.SupportedFeatures, "tools")
+		cm.Tools = &tools
+	}
+	if m.Pricing != nil {
+		cm.PromptPrice = string(m.Pricing.Prompt)
+		cm.CompletionPrice = string(m.Pricing.Completion)
+	}
+	return cm
 }
 
 // EmbeddingRequest represents the /embeddings API request.
@@ -144,19 +323,26 @@ type ChatOptions struct {
 	Temperature *float64
 	MaxTokens   *int
 	TopP        *float64
-	FilePath    string    // Optional file to include in context
+	Files       []string  // Optional files to include in context
+	Images      []string  // Images (paths or URLs) attached to the user turn
+	Pages       string    // PDF page selection for Files, e.g. "1-5" (empty = all)
 	Context     []Message // Previous messages for context
+	Sources     []Source  // Retrieved chunks injected as grounding context
+	NoFallback  bool      // use only Model, ignoring ClientConfig.Fallbacks
+	NoRetry     bool      // send once, bypassing retries and the circuit breaker
+	NoCache     bool      // bypass the response cache
 }
 
-// APIError represents an error response from the API.
-type APIError struct {
-	StatusCode int
-	Body       string
+// Source is a retrieved document chunk used for retrieval-augmented chat.
+type Source struct {
+	Ref   string  `json:"ref"` // attribution, e.g. "docs/deploy.md:10-42"
+	Text  string  `json:"text"`
+	Score float64 `json:"score"`
 }
 
-func (e *APIError) Error() string {
-	return fmt.Sprintf("API error: %d - %s", e.StatusCode, e.Body)
-}
+// DefaultVisionModel is the last-resort model for image requests when neither
+// ClientConfig.VisionModel nor the catalog names a vision-capable model.
+const DefaultVisionModel = "kimi"
 
 // modelAliases maps short names to full Synthetic model IDs.
 var modelAliases = map[string]string{ //nolint:gochecknoglobals // read-only lookup table, idiomatic Go
@@ -174,15 +360,34 @@ var modelAliases = map[string]string{ //nolint:gochecknoglobals // read-only loo
 	"llama":    "hf:meta-llama/Llama-3.3-70B-Instruct",
 }
 
-// ModelAliases returns a copy of the model alias map.
+// userAliases holds aliases from config; they override modelAliases.
+var userAliases map[string]string //nolint:gochecknoglobals // set once from config at startup
+
+// SetUserAliases installs aliases from config (models.aliases), replacing any
+// previously set. User aliases extend the built-ins and win on conflict.
+func SetUserAliases(aliases map[string]string) {
+	userAliases = maps.Clone(aliases)
+}
+
+// BuiltinModelAliases returns a copy of the compiled-in alias map.
+func BuiltinModelAliases() map[string]string {
+	return maps.Clone(modelAliases)
+}
+
+// ModelAliases returns a copy of the merged alias map: built-ins overlaid
+// with user aliases.
 func ModelAliases() map[string]string {
-	result := make(map[string]string, len(modelAliases))
+	result := make(map[string]string, len(modelAliases)+len(userAliases))
 	maps.Copy(result, modelAliases)
+	maps.Copy(result, userAliases)
 	return result
 }
 
 // ResolveModel resolves a model alias to its full name, or returns the input if not an alias.
 func ResolveModel(model string) string {
+	if resolved, ok := userAliases[model]; ok {
+		return resolved
+	}
 	if resolved, ok := modelAliases[model]; ok {
 		return resolved
 	}
@@ -211,6 +416,7 @@ type SearchRequest struct {
 // SearchResponse represents the /v2/search API response.
 type SearchResponse struct {
 	Results []SearchResult `json:"results"`
+	Cache   cache.Info     `json:"-"` // cache lookup for this response
 }
 
 // SearchResult represents a single search result.
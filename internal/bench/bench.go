@@ -0,0 +1,212 @@
+// Package bench measures model latency and throughput: time to first token
+// and generation speed across prompt sizes and concurrency levels.
+package bench
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Size is a named prompt size, measured in approximate input tokens.
+type Size struct {
+	Name   string `json:"name"`
+	Tokens int    `json:"tokens"`
+}
+
+// Sizes lists the built-in prompt sizes.
+var Sizes = []Size{ //nolint:gochecknoglobals // read-only lookup table
+	{Name: "small", Tokens: 128},
+	{Name: "medium", Tokens: 2048},
+	{Name: "large", Tokens: 16384},
+}
+
+// LookupSize returns the built-in size with name.
+func LookupSize(name string) (Size, bool) {
+	for _, s := range Sizes {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Size{}, false
+}
+
+// tasks are the fixed instructions rotated across requests in a cell.
+var tasks = []string{ //nolint:gochecknoglobals // read-only prompt set
+	"Summarize the document above in three bullet points.",
+	"List the five most important facts in the document above.",
+	"Write a one-paragraph abstract of the document above.",
+}
+
+const filler = "The committee reviewed quarterly operations across the northern and southern " +
+	"regions, noting that shipping volumes rose while average delivery times fell. " +
+	"Staffing shortages in two warehouses were offset by overtime and temporary hires. " +
+	"Energy costs remained the largest variable expense, and a pilot of solar panels " +
+	"at the central depot reduced grid consumption by a fifth. "
+
+// Prompt returns the i-th benchmark prompt for size: filler text of roughly
+// size.Tokens tokens followed by one of the fixed tasks.
+func Prompt(size Size, i int) string {
+	n := max(1, size.Tokens*4/len(filler)) // ~4 characters per token
+	return strings.Repeat(filler, n) + "\n\n" + tasks[i%len(tasks)]
+}
+
+// Sample is the timing of one streamed completion.
+type Sample struct {
+	TTFT             time.Duration // time to first content token
+	Total            time.Duration // time to the end of the stream
+	CompletionTokens int
+}
+
+// TokensPerSec is the generation rate after the first token.
+func (s Sample) TokensPerSec() float64 {
+	gen := s.Total - s.TTFT
+	if gen <= 0 || s.CompletionTokens == 0 {
+		return 0
+	}
+	return float64(s.CompletionTokens) / gen.Seconds()
+}
+
+// StreamFunc sends one streamed completion and reports its timing.
+type StreamFunc func(ctx context.Context, model, prompt string, maxTokens int) (Sample, error)
+
+// Config selects what to measure. Every combination of model, size and
+// concurrency level is one cell of Requests requests.
+type Config struct {
+	Models      []string
+	Sizes       []Size
+	Concurrency []int
+	Requests    int           // requests per cell
+	MaxTokens   int           // completion cap per request
+	Timeout     time.Duration // per request (0 = none)
+	Progress    func(Row)     // called after each cell (optional)
+}
+
+// Percentiles holds p50/p95/p99 of a metric.
+type Percentiles struct {
+	P50 float64 `json:"p50"`
+	P95 float64 `json:"p95"`
+	P99 float64 `json:"p99"`
+}
+
+// Row is the result of one cell.
+type Row struct {
+	Model        string      `json:"model"`
+	Size         string      `json:"size"`
+	InputTokens  int         `json:"input_tokens"`
+	Concurrency  int         `json:"concurrency"`
+	Requests     int         `json:"requests"`
+	Errors       int         `json:"errors"`
+	ErrorRate    float64     `json:"error_rate"`
+	TTFTMS       Percentiles `json:"ttft_ms"`
+	TokensPerSec Percentiles `json:"tokens_per_sec"`
+	FirstError   string      `json:"first_error,omitempty"`
+}
+
+// Report is one benchmark run.
+type Report struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Requests    int       `json:"requests_per_cell"`
+	MaxTokens   int       `json:"max_tokens"`
+	Rows        []Row     `json:"rows"`
+}
+
+// Run measures every cell in cfg, in model, size, concurrency order.
+func Run(ctx context.Context, stream StreamFunc, cfg Config) (Report, error) {
+	if len(cfg.Models) == 0 || len(cfg.Sizes) == 0 || len(cfg.Concurrency) == 0 {
+		return Report{}, fmt.Errorf("bench needs at least one model, size and concurrency level")
+	}
+	if cfg.Requests <= 0 {
+		return Report{}, fmt.Errorf("requests per cell must be positive, got %d", cfg.Requests)
+	}
+
+	report := Report{GeneratedAt: time.Now(), Requests: cfg.Requests, MaxTokens: cfg.MaxTokens}
+	for _, model := range cfg.Models {
+		for _, size := range cfg.Sizes {
+			for _, conc := range cfg.Concurrency {
+				if conc <= 0 {
+					return Report{}, fmt.Errorf("concurrency must be positive, got %d", conc)
+				}
+				row := runCell(ctx, stream, cfg, model, size, conc)
+				report.Rows = append(report.Rows, row)
+				if cfg.Progress != nil {
+					cfg.Progress(row)
+				}
+				if err := ctx.Err(); err != nil {
+					return report, err
+				}
+			}
+		}
+	}
+	return report, nil
+}
+
+func runCell(ctx context.Context, stream StreamFunc, cfg Config, model string, size Size, conc int) Row {
+	samples := make([]Sample, cfg.Requests)
+	errs := make([]error, cfg.Requests)
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(conc, cfg.Requests) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				reqCtx, cancel := ctx, context.CancelFunc(func() {})
+				if cfg.Timeout > 0 {
+					reqCtx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+				}
+				samples[i], errs[i] = stream(reqCtx, model, Prompt(size, i), cfg.MaxTokens)
+				cancel()
+			}
+		}()
+	}
+	for i := range cfg.Requests {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	row := Row{Model: model, Size: size.Name, InputTokens: size.Tokens, Concurrency: conc, Requests: cfg.Requests}
+	var ttft, tps []float64
+	for i, err := range errs {
+		if err != nil {
+			if row.Errors++; row.FirstError == "" {
+				row.FirstError = err.Error()
+			}
+			continue
+		}
+		ttft = append(ttft, float64(samples[i].TTFT.Milliseconds()))
+		tps = append(tps, samples[i].TokensPerSec())
+	}
+	row.ErrorRate = float64(row.Errors) / float64(row.Requests)
+	row.TTFTMS = percentiles(ttft)
+	row.TokensPerSec = percentiles(tps)
+	return row
+}
+
+func percentiles(values []float64) Percentiles {
+	if len(values) == 0 {
+		return Percentiles{}
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	return Percentiles{
+		P50: Percentile(sorted, 50),
+		P95: Percentile(sorted, 95),
+		P99: Percentile(sorted, 99),
+	}
+}
+
+// Percentile returns the nearest-rank p-th percentile of sorted values.
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
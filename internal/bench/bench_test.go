@@ -0,0 +1,105 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	sorted := make([]float64, 100)
+	for i := range sorted {
+		sorted[i] = float64(i + 1)
+	}
+	for p, want := range map[float64]float64{50: 50, 95: 95, 99: 99, 100: 100} {
+		if got := Percentile(sorted, p); got != want {
+			t.Errorf("Percentile(%v) = %v, want %v", p, got, want)
+		}
+	}
+	if got := Percentile([]float64{7}, 99); got != 7 {
+		t.Errorf("single value p99 = %v", got)
+	}
+}
+
+func TestRunCellsAndErrors(t *testing.T) {
+	var calls, inFlight, peak atomic.Int32
+	stream := func(_ context.Context, model, prompt string, maxTokens int) (Sample, error) {
+		n := calls.Add(1)
+		cur := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			old := peak.Load()
+			if cur <= old || peak.CompareAndSwap(old, cur) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		if model == "flaky" && n%2 == 0 {
+			return Sample{}, errors.New("503")
+		}
+		return Sample{TTFT: 100 * time.Millisecond, Total: 1100 * time.Millisecond, CompletionTokens: 50}, nil
+	}
+
+	small, _ := LookupSize("small")
+	var progress int
+	report, err := Run(context.Background(), stream, Config{
+		Models:      []string{"fast", "flaky"},
+		Sizes:       []Size{small},
+		Concurrency: []int{1, 4},
+		Requests:    8,
+		MaxTokens:   64,
+		Progress:    func(Row) { progress++ },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Rows) != 4 || progress != 4 || calls.Load() != 32 {
+		t.Fatalf("rows=%d progress=%d calls=%d", len(report.Rows), progress, calls.Load())
+	}
+	if peak.Load() > 4 {
+		t.Fatalf("concurrency exceeded: %d", peak.Load())
+	}
+
+	fast := report.Rows[0]
+	if fast.Errors != 0 || fast.TTFTMS.P50 != 100 || fast.TokensPerSec.P99 != 50 {
+		t.Fatalf("fast row = %+v", fast)
+	}
+	flaky := report.Rows[2]
+	if flaky.Model != "flaky" || flaky.ErrorRate == 0 || flaky.FirstError != "503" {
+		t.Fatalf("flaky row = %+v", flaky)
+	}
+
+	table := RenderTable(report)
+	if !strings.Contains(table, "TTFT p50") || !strings.Contains(table, "flaky") {
+		t.Fatalf("table:\n%s", table)
+	}
+}
+
+func TestHistoryRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runs", "bench.jsonl")
+	report := Report{GeneratedAt: time.Now(), MaxTokens: 64, Rows: []Row{{Model: "a", Size: "small", Concurrency: 1}}}
+	for range 2 {
+		if err := AppendHistory(path, report); err != nil {
+			t.Fatal(err)
+		}
+	}
+	records, err := LoadHistory(path)
+	if err != nil || len(records) != 2 || records[1].Model != "a" || records[1].MaxTokens != 64 {
+		t.Fatalf("LoadHistory = %+v, %v", records, err)
+	}
+}
+
+func TestPromptSizes(t *testing.T) {
+	large, _ := LookupSize("large")
+	small, _ := LookupSize("small")
+	if len(Prompt(large, 0)) <= len(Prompt(small, 0))*50 {
+		t.Fatal("large prompt should be much longer than small")
+	}
+	if Prompt(small, 0) == Prompt(small, 1) {
+		t.Fatal("prompts should rotate tasks")
+	}
+}
@@ -0,0 +1,85 @@
+package bench
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// RenderTable returns the report as an aligned plain-text table.
+func RenderTable(r Report) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Model\tSize\tConc\tErr%\tTTFT p50\tp95\tp99\tTok/s p50\tp95\tp99\t")
+	for _, row := range r.Rows {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%.0f\t%.0f\t%.0f\t%.0f\t%.1f\t%.1f\t%.1f\t\n",
+			row.Model, row.Size, row.Concurrency, row.ErrorRate*100,
+			row.TTFTMS.P50, row.TTFTMS.P95, row.TTFTMS.P99,
+			row.TokensPerSec.P50, row.TokensPerSec.P95, row.TokensPerSec.P99)
+	}
+	_ = w.Flush()
+	return b.String()
+}
+
+// HistoryRecord is one row of one run in the benchmark history.
+type HistoryRecord struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	MaxTokens   int       `json:"max_tokens"`
+	Row
+}
+
+// AppendHistory appends one jsonl record per row in report.
+func AppendHistory(path string, report Report) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("prepare history dir: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open history file: %w", err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	for _, row := range report.Rows {
+		rec := HistoryRecord{GeneratedAt: report.GeneratedAt, MaxTokens: report.MaxTokens, Row: row}
+		if err := enc.Encode(rec); err != nil {
+			return fmt.Errorf("append history record: %w", err)
+		}
+	}
+	return nil
+}
+
+// LoadHistory reads jsonl benchmark records, skipping malformed lines.
+func LoadHistory(path string) ([]HistoryRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("open history file: %w", err)
+	}
+	defer f.Close()
+
+	var records []HistoryRecord
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+		var rec HistoryRecord
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			continue
+		}
+		records = append(records, rec)
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("scan history file: %w", err)
+	}
+	return records, nil
+}
@@ -0,0 +1,269 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache is a file-backed key/value store with TTL expiry.
+// Each entry is a JSON file named after the SHA-256 of its key.
+type Cache struct {
+	dir      string
+	ttl      time.Duration
+	maxBytes int64
+	now      func() time.Time
+
+	mu   sync.Mutex
+	size int64 // bytes on disk as of the last scan plus later writes (-1 = unknown)
+}
+
+// Entry is the on-disk representation of a cached value.
+type Entry struct {
+	Key      string          `json:"key"`
+	StoredAt time.Time       `json:"stored_at"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// Info describes a cache lookup for callers that report cache metadata.
+type Info struct {
+	Hit      bool
+	StoredAt time.Time
+	Age      time.Duration
+	TTL      time.Duration
+}
+
+// Stats summarises the entries on disk.
+type Stats struct {
+	Dir     string    `json:"dir"`
+	Entries int       `json:"entries"`
+	Bytes   int64     `json:"bytes"`
+	Expired int       `json:"expired"`
+	Oldest  time.Time `json:"oldest,omitzero"`
+	Newest  time.Time `json:"newest,omitzero"`
+}
+
+// New creates a cache rooted at dir. A ttl <= 0 disables expiry.
+func New(dir string, ttl time.Duration) *Cache {
+	return &Cache{dir: dir, ttl: ttl, now: time.Now, size: -1}
+}
+
+// SetMaxBytes caps the total size of the entries; Put evicts the least
+// recently written entries beyond it. n <= 0 removes the cap.
+func (c *Cache) SetMaxBytes(n int64) {
+	c.maxBytes = n
+}
+
+// Dir returns the directory holding the entries.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// DefaultDir returns the syn cache directory for the given namespace,
+// e.g. ~/.cache/syn/search on Linux.
+func DefaultDir(namespace string) (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve cache dir: %w", err)
+	}
+	return filepath.Join(base, "syn", namespace), nil
+}
+
+// TTL returns the configured time-to-live.
+func (c *Cache) TTL() time.Duration {
+	return c.ttl
+}
+
+// Get loads the value stored under key into v.
+// Missing, expired, and unreadable entries are reported as misses.
+func (c *Cache) Get(key string, v any) (Info, error) {
+	info := Info{TTL: c.ttl}
+
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return info, nil
+		}
+		return info, fmt.Errorf("read cache entry: %w", err)
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Key != key {
+		return info, nil
+	}
+
+	age := c.now().Sub(entry.StoredAt)
+	if c.ttl > 0 && age > c.ttl {
+		return info, nil
+	}
+
+	if err := json.Unmarshal(entry.Value, v); err != nil {
+		return info, nil
+	}
+
+	info.Hit = true
+	info.StoredAt = entry.StoredAt
+	info.Age = age
+	return info, nil
+}
+
+// Put stores v under key, replacing any existing entry.
+func (c *Cache) Put(key string, v any) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal cache value: %w", err)
+	}
+
+	data, err := json.Marshal(Entry{Key: key, StoredAt: c.now(), Value: value})
+	if err != nil {
+		return fmt.Errorf("marshal cache entry: %w", err)
+	}
+
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return fmt.Errorf("create cache dir: %w", err)
+	}
+
+	// Write to a temp file and rename so concurrent readers never see partial entries.
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		return fmt.Errorf("commit cache entry: %w", err)
+	}
+	return c.grow(int64(len(data)))
+}
+
+// grow records n newly written bytes and evicts the oldest entries once the
+// cache exceeds maxBytes. Overwritten entries are over-counted until the
+// next eviction rescans the directory.
+func (c *Cache) grow(n int64) error {
+	if c.maxBytes <= 0 {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.size >= 0 {
+		c.size += n
+		if c.size <= c.maxBytes {
+			return nil
+		}
+	}
+
+	files, err := c.files()
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(files, func(a, b fileInfo) int { return a.modTime.Compare(b.modTime) })
+
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	// Evict down to 90% of the cap so the next few writes do not rescan.
+	for _, f := range files {
+		if total <= c.maxBytes*9/10 {
+			break
+		}
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("evict cache entry: %w", err)
+		}
+		total -= f.size
+	}
+	c.size = total
+	return nil
+}
+
+// Stats scans the cache directory. A missing directory is an empty cache.
+func (c *Cache) Stats() (Stats, error) {
+	stats := Stats{Dir: c.dir}
+	files, err := c.files()
+	if err != nil {
+		return stats, err
+	}
+	for _, f := range files {
+		stats.Entries++
+		stats.Bytes += f.size
+		if c.ttl > 0 && c.now().Sub(f.modTime) > c.ttl {
+			stats.Expired++
+		}
+		if stats.Oldest.IsZero() || f.modTime.Before(stats.Oldest) {
+			stats.Oldest = f.modTime
+		}
+		if f.modTime.After(stats.Newest) {
+			stats.Newest = f.modTime
+		}
+	}
+	return stats, nil
+}
+
+// Clear removes every entry and returns how many were removed.
+func (c *Cache) Clear() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	files, err := c.files()
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, f := range files {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("remove cache entry: %w", err)
+		}
+		removed++
+	}
+	c.size = 0
+	return removed, nil
+}
+
+type fileInfo struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// files lists the entry files in the cache directory.
+func (c *Cache) files() ([]fileInfo, error) {
+	dirents, err := os.ReadDir(c.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read cache dir: %w", err)
+	}
+	var files []fileInfo
+	for _, d := range dirents {
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+			continue
+		}
+		info, err := d.Info()
+		if err != nil {
+			continue // removed concurrently
+		}
+		files = append(files, fileInfo{path: filepath.Join(c.dir, d.Name()), size: info.Size(), modTime: info.ModTime()})
+	}
+	return files, nil
+}
+
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPutGet(t *testing.T) {
+	c := New(t.TempDir(), time.Hour)
+
+	if err := c.Put("k", map[string]int{"n": 1}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	var got map[string]int
+	info, err := c.Get("k", &got)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !info.Hit || got["n"] != 1 {
+		t.Fatalf("expected hit with n=1, got hit=%v value=%v", info.Hit, got)
+	}
+
+	info, err = c.Get("missing", &got)
+	if err != nil || info.Hit {
+		t.Fatalf("expected miss for unknown key, got hit=%v err=%v", info.Hit, err)
+	}
+}
+
+func TestGetExpired(t *testing.T) {
+	c := New(t.TempDir(), time.Minute)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return start }
+
+	if err := c.Put("k", "v"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	c.now = func() time.Time { return start.Add(2 * time.Minute) }
+	var got string
+	info, err := c.Get("k", &got)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if info.Hit {
+		t.Fatalf("expected expired entry to miss")
+	}
+}
+
+func TestMaxBytesEvictsOldest(t *testing.T) {
+	c := New(t.TempDir(), 0)
+	c.SetMaxBytes(1000)
+
+	value := strings.Repeat("x", 150) // ~250 bytes per entry on disk
+	base := time.Now().Add(-time.Hour)
+	for i := range 6 {
+		key := fmt.Sprintf("k%d", i)
+		if err := c.Put(key, value); err != nil {
+			t.Fatalf("Put(%s) error = %v", key, err)
+		}
+		// Order entries by write time deterministically.
+		stamp := base.Add(time.Duration(i) * time.Minute)
+		if err := os.Chtimes(c.path(key), stamp, stamp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := c.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Bytes > 1000 || stats.Entries == 0 {
+		t.Fatalf("stats after eviction = %+v", stats)
+	}
+	var got string
+	if info, _ := c.Get("k5", &got); !info.Hit {
+		t.Fatal("newest entry was evicted")
+	}
+	if info, _ := c.Get("k0", &got); info.Hit {
+		t.Fatal("oldest entry survived eviction")
+	}
+}
+
+func TestStatsAndClear(t *testing.T) {
+	c := New(filepath.Join(t.TempDir(), "missing"), time.Minute)
+	if stats, err := c.Stats(); err != nil || stats.Entries != 0 {
+		t.Fatalf("Stats() on missing dir = %+v, %v", stats, err)
+	}
+
+	for _, k := range []string{"a", "b"} {
+		if err := c.Put(k, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	stats, err := c.Stats()
+	if err != nil || stats.Entries != 2 || stats.Expired != 2 || stats.Bytes == 0 {
+		t.Fatalf("Stats() = %+v, %v", stats, err)
+	}
+
+	if n, err := c.Clear(); err != nil || n != 2 {
+		t.Fatalf("Clear() = %d, %v", n, err)
+	}
+	if stats, _ := c.Stats(); stats.Entries != 0 {
+		t.Fatalf("entries after Clear = %d", stats.Entries)
+	}
+}
@@ -0,0 +1,137 @@
+// Package cassette records HTTP exchanges to files and replays them without
+// network access. Recorder and Replayer satisfy app.HTTPDoer.
+//
+// Each exchange is stored as <key>-<n>.json in the cassette directory, where
+// key hashes the request method, path, query and body (not the host, so a
+// cassette replays against any base URL) and n counts repeats of the same
+// request, such as retries. Secrets are redacted before anything is written.
+package cassette
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrNotRecorded is returned by Replayer for requests missing from the cassette.
+var ErrNotRecorded = errors.New("no recorded response")
+
+// Redacted replaces secrets in recorded exchanges.
+const Redacted = "REDACTED"
+
+// Interaction is one recorded request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is the recorded form of an HTTP request.
+type Request struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    Body        `json:"body"`
+}
+
+// Response is the recorded form of an HTTP response.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       Body        `json:"body"`
+}
+
+// Body holds a message body as text, or base64 when it is not valid UTF-8.
+type Body struct {
+	Text   string `json:"text,omitempty"`
+	Base64 string `json:"base64,omitempty"`
+}
+
+func newBody(data []byte) Body {
+	if utf8.Valid(data) {
+		return Body{Text: string(data)}
+	}
+	return Body{Base64: base64.StdEncoding.EncodeToString(data)}
+}
+
+// Bytes returns the decoded body.
+func (b Body) Bytes() ([]byte, error) {
+	if b.Base64 != "" {
+		return base64.StdEncoding.DecodeString(b.Base64)
+	}
+	return []byte(b.Text), nil
+}
+
+// sensitiveHeaders are replaced wholesale when recorded.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "X-Api-Key", "Api-Key", "Cookie", "Set-Cookie"} //nolint:gochecknoglobals // constant list
+
+// redactor removes secrets from recorded values.
+type redactor struct {
+	secrets []string
+}
+
+func (r redactor) string(s string) string {
+	for _, secret := range r.secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, Redacted)
+		}
+	}
+	return s
+}
+
+func (r redactor) headers(h http.Header) http.Header {
+	out := make(http.Header, len(h))
+	for name, values := range h {
+		for _, v := range values {
+			out.Add(name, r.string(v))
+		}
+	}
+	for _, name := range sensitiveHeaders {
+		if out.Get(name) != "" {
+			out.Set(name, Redacted)
+		}
+	}
+	return out
+}
+
+func (r redactor) bytes(data []byte) []byte {
+	for _, secret := range r.secrets {
+		if secret != "" {
+			data = bytes.ReplaceAll(data, []byte(secret), []byte(Redacted))
+		}
+	}
+	return data
+}
+
+// requestKey identifies a request by method, path, query and body. Secrets
+// are redacted first so recorded and replayed keys agree.
+func requestKey(req *http.Request, body []byte, r redactor) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s?%s\n", req.Method, req.URL.Path, r.string(req.URL.RawQuery))
+	h.Write(r.bytes(body))
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// readBody returns req's body and restores it for the next reader.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+func fileName(key string, n int) string {
+	return fmt.Sprintf("%s-%d.json", key, n)
+}
@@ -0,0 +1,126 @@
+package cassette
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const secret = "sk-test-123"
+
+func send(t *testing.T, d Doer, url, body string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+secret)
+	resp, err := d.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(data)
+}
+
+func TestRecordThenReplayOffline(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if hits.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Set-Cookie", "session=abc")
+		io.WriteString(w, "echo:"+string(body)+" key="+secret)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	rec, err := NewRecorder(dir, srv.Client(), secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, _ := send(t, rec, srv.URL+"/v1/chat", "hi"); code != http.StatusServiceUnavailable {
+		t.Fatalf("first status = %d", code)
+	}
+	send(t, rec, srv.URL+"/v1/chat", "hi")
+	send(t, rec, srv.URL+"/v1/chat", "other")
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 3 {
+		t.Fatalf("recorded %d files, want 3", len(files))
+	}
+	for _, f := range files {
+		data, _ := os.ReadFile(f)
+		if strings.Contains(string(data), secret) || strings.Contains(string(data), "session=abc") {
+			t.Fatalf("%s leaks a secret:\n%s", filepath.Base(f), data)
+		}
+	}
+
+	srv.Close() // replay must not need the network
+	rep, err := NewReplayer(dir, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Different host: cassettes are keyed without it.
+	for _, want := range []struct {
+		body string
+		code int
+		resp string
+	}{
+		{"hi", http.StatusServiceUnavailable, ""},
+		{"hi", http.StatusOK, "echo:hi key=" + Redacted},
+		{"hi", http.StatusOK, "echo:hi key=" + Redacted}, // last response repeats
+		{"other", http.StatusOK, "echo:other key=" + Redacted},
+	} {
+		code, body := send(t, rep, "http://replay.invalid/v1/chat", want.body)
+		if code != want.code || body != want.resp {
+			t.Fatalf("replay %q = %d %q, want %d %q", want.body, code, body, want.code, want.resp)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "http://replay.invalid/v1/chat", strings.NewReader("unseen"))
+	if _, err := rep.Do(req); !errors.Is(err, ErrNotRecorded) {
+		t.Fatalf("unrecorded request: err = %v", err)
+	}
+}
+
+func TestBinaryBodyRoundTrip(t *testing.T) {
+	payload := []byte{0xff, 0x00, 0xfe}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(payload)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	rec, err := NewRecorder(dir, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	send(t, rec, srv.URL, "")
+
+	rep, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, body := send(t, rep, srv.URL, ""); body != string(payload) {
+		t.Fatalf("body = %x, want %x", body, payload)
+	}
+}
+
+func TestNewReplayerMissingDir(t *testing.T) {
+	if _, err := NewReplayer(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatal("expected error for missing dir")
+	}
+}
@@ -0,0 +1,119 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Doer is the HTTP interface Recorder wraps; app.HTTPDoer has the same shape.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Recorder passes requests to the next Doer and writes each exchange to the
+// cassette directory once its response body is fully read or closed.
+// Recording into a directory again overwrites matching exchanges.
+type Recorder struct {
+	dir     string
+	next    Doer
+	redact  redactor
+	mu      sync.Mutex
+	counter map[string]int
+}
+
+// NewRecorder creates dir and returns a Recorder that writes exchanges there.
+// Every occurrence of secrets is replaced with Redacted, as are credential
+// headers such as Authorization.
+func NewRecorder(dir string, next Doer, secrets ...string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create cassette dir: %w", err)
+	}
+	return &Recorder{dir: dir, next: next, redact: redactor{secrets: secrets}, counter: map[string]int{}}, nil
+}
+
+// Do sends req and records the exchange. Transport errors are not recorded.
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.next.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	key := requestKey(req, body, r.redact)
+	r.mu.Lock()
+	n := r.counter[key]
+	r.counter[key]++
+	r.mu.Unlock()
+
+	resp.Body = &recordingBody{
+		ReadCloser: resp.Body,
+		path:       filepath.Join(r.dir, fileName(key, n)),
+		interaction: Interaction{
+			Request: Request{
+				Method:  req.Method,
+				URL:     r.redact.string(req.URL.String()),
+				Headers: r.redact.headers(req.Header),
+				Body:    newBody(r.redact.bytes(body)),
+			},
+			Response: Response{
+				StatusCode: resp.StatusCode,
+				Headers:    r.redact.headers(resp.Header),
+			},
+		},
+		redact: r.redact,
+	}
+	return resp, nil
+}
+
+// recordingBody copies what the caller reads and writes the cassette file at
+// EOF or Close, whichever comes first. A body closed early is recorded as
+// read, so replay reproduces what the caller saw.
+type recordingBody struct {
+	io.ReadCloser
+	path        string
+	interaction Interaction
+	redact      redactor
+	buf         bytes.Buffer
+	once        sync.Once
+	err         error
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	if err == io.EOF {
+		b.save()
+	}
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	b.save()
+	if err := b.ReadCloser.Close(); err != nil {
+		return err
+	}
+	return b.err
+}
+
+func (b *recordingBody) save() {
+	b.once.Do(func() {
+		b.interaction.Response.Body = newBody(b.redact.bytes(b.buf.Bytes()))
+		data, err := json.MarshalIndent(b.interaction, "", "  ")
+		if err != nil {
+			b.err = fmt.Errorf("encode cassette: %w", err)
+			return
+		}
+		if err := os.WriteFile(b.path, data, 0o600); err != nil {
+			b.err = fmt.Errorf("write cassette: %w", err)
+		}
+	})
+}
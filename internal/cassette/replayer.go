@@ -0,0 +1,123 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Replayer serves recorded responses without touching the network. Repeats
+// of a request are answered in recorded order; once they run out, the last
+// response is served again.
+type Replayer struct {
+	redact  redactor
+	mu      sync.Mutex
+	entries map[string][]Interaction
+	served  map[string]int
+}
+
+// NewReplayer loads every cassette in dir. secrets must match those given
+// to the Recorder when they appear in request bodies or query strings.
+func NewReplayer(dir string, secrets ...string) (*Replayer, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("list cassettes: %w", err)
+	}
+	if len(paths) == 0 {
+		if _, err := os.Stat(dir); err != nil {
+			return nil, fmt.Errorf("open cassette dir: %w", err)
+		}
+	}
+
+	type numbered struct {
+		n           int
+		interaction Interaction
+	}
+	byKey := map[string][]numbered{}
+	for _, path := range paths {
+		key, n, ok := parseFileName(filepath.Base(path))
+		if !ok {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read cassette: %w", err)
+		}
+		var in Interaction
+		if err := json.Unmarshal(data, &in); err != nil {
+			return nil, fmt.Errorf("parse cassette %s: %w", filepath.Base(path), err)
+		}
+		byKey[key] = append(byKey[key], numbered{n: n, interaction: in})
+	}
+
+	entries := make(map[string][]Interaction, len(byKey))
+	for key, list := range byKey {
+		sort.Slice(list, func(i, j int) bool { return list[i].n < list[j].n })
+		for _, e := range list {
+			entries[key] = append(entries[key], e.interaction)
+		}
+	}
+	return &Replayer{redact: redactor{secrets: secrets}, entries: entries, served: map[string]int{}}, nil
+}
+
+// Do returns the recorded response for req, or an error wrapping
+// ErrNotRecorded.
+func (r *Replayer) Do(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	key := requestKey(req, body, r.redact)
+
+	r.mu.Lock()
+	list := r.entries[key]
+	i := min(r.served[key], len(list)-1)
+	r.served[key]++
+	r.mu.Unlock()
+
+	if len(list) == 0 {
+		return nil, fmt.Errorf("%w for %s %s", ErrNotRecorded, req.Method, req.URL.Path)
+	}
+	rec := list[i].Response
+	data, err := rec.Body.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("decode cassette body: %w", err)
+	}
+	header := rec.Headers.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.StatusCode, http.StatusText(rec.StatusCode)),
+		StatusCode:    rec.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       req,
+	}, nil
+}
+
+// parseFileName splits "<key>-<n>.json".
+func parseFileName(name string) (string, int, bool) {
+	base := strings.TrimSuffix(name, ".json")
+	i := strings.LastIndexByte(base, '-')
+	if i <= 0 {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(base[i+1:])
+	if err != nil || n < 0 {
+		return "", 0, false
+	}
+	return base[:i], n, true
+}
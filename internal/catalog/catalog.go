@@ -0,0 +1,194 @@
+// Package catalog merges model capability metadata from a built-in table,
+// the /models API response and an optional user file.
+package catalog
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Metadata sources, in increasing precedence.
+const (
+	SourceBuiltin = "builtin"
+	SourceAPI     = "api"
+	SourceUser    = "user"
+)
+
+//go:embed builtin.yaml
+var builtinYAML []byte
+
+// Model describes what a model supports. Zero fields are unknown.
+type Model struct {
+	ID               string   `yaml:"id" json:"id"`
+	Name             string   `yaml:"name,omitempty" json:"name,omitempty"`
+	ContextLength    int      `yaml:"context_length,omitempty" json:"context_length,omitempty"`
+	MaxOutput        int      `yaml:"max_output,omitempty" json:"max_output,omitempty"`
+	InputModalities  []string `yaml:"input_modalities,omitempty" json:"input_modalities,omitempty"`
+	OutputModalities []string `yaml:"output_modalities,omitempty" json:"output_modalities,omitempty"`
+	Tools            *bool    `yaml:"tools,omitempty" json:"tools,omitempty"`
+	PromptPrice      string   `yaml:"prompt_price,omitempty" json:"prompt_price,omitempty"`         // input price as reported or configured, e.g. "$0.55/M"
+	CompletionPrice  string   `yaml:"completion_price,omitempty" json:"completion_price,omitempty"` // output price, same format
+	Sources          []string `yaml:"-" json:"sources"`
+}
+
+// Vision reports whether the model accepts image input.
+func (m Model) Vision() bool {
+	return slices.Contains(m.InputModalities, "image")
+}
+
+// Catalog is an ordered set of model capabilities keyed by model ID.
+type Catalog struct {
+	models map[string]Model
+	order  []string
+	user   map[string]Model // fields set by the user file, re-applied over later sources
+}
+
+type file struct {
+	Models []Model `yaml:"models"`
+}
+
+// Builtin returns a catalog holding only the built-in capability table.
+func Builtin() *Catalog {
+	c := &Catalog{models: map[string]Model{}}
+	if err := c.mergeYAML(builtinYAML, SourceBuiltin); err != nil {
+		panic(fmt.Sprintf("catalog: invalid builtin.yaml: %v", err))
+	}
+	return c
+}
+
+// DefaultPath returns the user capability file, ~/.config/syn/models.yaml.
+func DefaultPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "syn", "models.yaml")
+}
+
+// Load returns the built-in catalog overlaid with the user file at path.
+// A missing file is not an error.
+func Load(path string) (*Catalog, error) {
+	c := Builtin()
+	if path == "" {
+		return c, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model catalog %s: %w", path, err)
+	}
+	if err := c.mergeYAML(data, SourceUser); err != nil {
+		return nil, fmt.Errorf("invalid model catalog %s: %w", path, err)
+	}
+	return c, nil
+}
+
+func (c *Catalog) mergeYAML(data []byte, source string) error {
+	var f file
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	for _, m := range f.Models {
+		if m.ID == "" {
+			return errors.New("model entry without id")
+		}
+		c.Merge(m, source)
+	}
+	return nil
+}
+
+// Merge overlays the known fields of m onto the entry for m.ID. Precedence
+// is per field: a field the user file set keeps the user's value, while
+// fields the user left unset take metadata from any source.
+func (c *Catalog) Merge(m Model, source string) {
+	cur, ok := c.models[m.ID]
+	if !ok {
+		c.order = append(c.order, m.ID)
+		cur = Model{ID: m.ID}
+	}
+	cur.overlay(m)
+	if source == SourceUser {
+		u := c.user[m.ID]
+		u.overlay(m)
+		if c.user == nil {
+			c.user = map[string]Model{}
+		}
+		c.user[m.ID] = u
+	} else if u, ok := c.user[m.ID]; ok {
+		cur.overlay(u)
+	}
+
+	if !slices.Contains(cur.Sources, source) {
+		cur.Sources = append(cur.Sources, source)
+	}
+	c.models[m.ID] = cur
+}
+
+// overlay copies the known capability fields of src onto m.
+func (m *Model) overlay(src Model) {
+	if src.Name != "" {
+		m.Name = src.Name
+	}
+	if src.ContextLength != 0 {
+		m.ContextLength = src.ContextLength
+	}
+	if src.MaxOutput != 0 {
+		m.MaxOutput = src.MaxOutput
+	}
+	if len(src.InputModalities) > 0 {
+		m.InputModalities = slices.Clone(src.InputModalities)
+	}
+	if len(src.OutputModalities) > 0 {
+		m.OutputModalities = slices.Clone(src.OutputModalities)
+	}
+	if src.Tools != nil {
+		tools := *src.Tools
+		m.Tools = &tools
+	}
+	if src.PromptPrice != "" {
+		m.PromptPrice = src.PromptPrice
+	}
+	if src.CompletionPrice != "" {
+		m.CompletionPrice = src.CompletionPrice
+	}
+}
+
+// Lookup returns the capabilities recorded for id.
+func (c *Catalog) Lookup(id string) (Model, bool) {
+	m, ok := c.models[id]
+	return m, ok
+}
+
+// SupportsVision reports whether id is known to accept image input.
+func (c *Catalog) SupportsVision(id string) bool {
+	m, ok := c.models[id]
+	return ok && m.Vision()
+}
+
+// VisionModels returns vision-capable model IDs in catalog order.
+func (c *Catalog) VisionModels() []string {
+	var ids []string
+	for _, id := range c.order {
+		if c.models[id].Vision() {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Models returns all entries in catalog order.
+func (c *Catalog) Models() []Model {
+	out := make([]Model, 0, len(c.order))
+	for _, id := range c.order {
+		out = append(out, c.models[id])
+	}
+	return out
+}
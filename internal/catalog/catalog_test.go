@@ -0,0 +1,66 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestBuiltinVisionModels(t *testing.T) {
+	c := Builtin()
+	got := c.VisionModels()
+	if len(got) == 0 || got[0] != "hf:moonshotai/Kimi-K2.5" {
+		t.Fatalf("VisionModels() = %v", got)
+	}
+	if c.SupportsVision("hf:zai-org/GLM-4.7") {
+		t.Fatal("GLM should not be vision-capable")
+	}
+}
+
+func TestUserEntriesWin(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "models.yaml")
+	data := "models:\n" +
+		"  - id: hf:zai-org/GLM-4.7\n" +
+		"    context_length: 100000\n" +
+		"    input_modalities: [text, image]\n" +
+		"  - id: hf:openai/gpt-oss-120b\n" +
+		"    name: GPT OSS\n" +
+		"  - id: hf:local/custom\n" +
+		"    prompt_price: $1/M\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// API metadata replaces built-in values but not user values.
+	noTools := false
+	c.Merge(Model{ID: "hf:zai-org/GLM-4.7", Name: "GLM 4.7", ContextLength: 202752, MaxOutput: 8192, InputModalities: []string{"text"}, Tools: &noTools}, SourceAPI)
+
+	m, ok := c.Lookup("hf:zai-org/GLM-4.7")
+	if !ok {
+		t.Fatal("GLM missing")
+	}
+	if m.ContextLength != 100000 || !m.Vision() || m.Name != "GLM 4.7" || m.MaxOutput != 8192 || m.Tools == nil || *m.Tools {
+		t.Fatalf("merged entry = %+v", m)
+	}
+	if !slices.Equal(m.Sources, []string{SourceBuiltin, SourceUser, SourceAPI}) {
+		t.Fatalf("sources = %v", m.Sources)
+	}
+	if m, _ := c.Lookup("hf:openai/gpt-oss-120b"); m.Name != "GPT OSS" || m.ContextLength != 131072 || m.Tools == nil || !*m.Tools {
+		t.Fatalf("partial user entry = %+v", m)
+	}
+	if m, _ := c.Lookup("hf:local/custom"); m.PromptPrice != "$1/M" {
+		t.Fatalf("custom entry = %+v", m)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	c, err := Load(filepath.Join(t.TempDir(), "none.yaml"))
+	if err != nil || len(c.Models()) != len(Builtin().Models()) {
+		t.Fatalf("Load(missing) = %v, %v", c, err)
+	}
+}
@@ -0,0 +1,108 @@
+// Package clipboard reads images from the system clipboard using external
+// tools: wl-paste (wl-clipboard) on Wayland and xclip on X11.
+package clipboard
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ErrNoImage is returned when the clipboard holds no image data.
+var ErrNoImage = errors.New("clipboard does not contain an image")
+
+// Seams for tests.
+var ( //nolint:gochecknoglobals // replaced in tests only
+	getenv   = os.Getenv
+	lookPath = exec.LookPath
+	run      = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		return exec.CommandContext(ctx, name, args...).Output() //nolint:gosec // G204: fixed tool names
+	}
+)
+
+// tool describes a clipboard reader command.
+type tool struct {
+	name      string
+	listTypes []string                       // prints available MIME types, one per line
+	read      func(mimeType string) []string // writes the clipboard contents for mimeType to stdout
+}
+
+// candidates returns the clipboard tools to try for the current session.
+func candidates() []tool {
+	wayland := tool{
+		name:      "wl-paste",
+		listTypes: []string{"--list-types"},
+		read:      func(t string) []string { return []string{"--no-newline", "--type", t} },
+	}
+	x11 := tool{
+		name:      "xclip",
+		listTypes: []string{"-selection", "clipboard", "-t", "TARGETS", "-o"},
+		read:      func(t string) []string { return []string{"-selection", "clipboard", "-t", t, "-o"} },
+	}
+
+	switch {
+	case getenv("WAYLAND_DISPLAY") != "":
+		return []tool{wayland, x11} // XWayland sessions may only have xclip
+	case getenv("DISPLAY") != "":
+		return []tool{x11}
+	default:
+		return nil
+	}
+}
+
+// ReadImage returns the image currently on the clipboard.
+func ReadImage(ctx context.Context) ([]byte, error) {
+	tools := candidates()
+	if len(tools) == 0 {
+		return nil, fmt.Errorf("no graphical session (WAYLAND_DISPLAY and DISPLAY are unset)")
+	}
+
+	for _, t := range tools {
+		if _, err := lookPath(t.name); err != nil {
+			continue
+		}
+
+		out, err := run(ctx, t.name, t.listTypes...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to list clipboard types: %w", t.name, err)
+		}
+		mimeType := pickImageType(string(out))
+		if mimeType == "" {
+			return nil, ErrNoImage
+		}
+
+		data, err := run(ctx, t.name, t.read(mimeType)...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to read clipboard: %w", t.name, err)
+		}
+		if len(bytes.TrimSpace(data)) == 0 {
+			return nil, ErrNoImage
+		}
+		return data, nil
+	}
+
+	return nil, fmt.Errorf("no clipboard tool found: install wl-clipboard (Wayland) or xclip (X11)")
+}
+
+// pickImageType selects an image MIME type from a tool's type listing,
+// preferring PNG, which screenshot tools put on the clipboard losslessly.
+func pickImageType(listing string) string {
+	var first string
+	for line := range strings.SplitSeq(listing, "\n") {
+		t := strings.TrimSpace(line)
+		if !strings.HasPrefix(t, "image/") {
+			continue
+		}
+		if t == "image/png" {
+			return t
+		}
+		if first == "" {
+			first = t
+		}
+	}
+	return first
+}
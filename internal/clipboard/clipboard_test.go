@@ -0,0 +1,86 @@
+package clipboard
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func fakeSession(t *testing.T, env map[string]string, installed []string, outputs map[string]string) *[][]string {
+	t.Helper()
+	oldGetenv, oldLookPath, oldRun := getenv, lookPath, run
+	t.Cleanup(func() { getenv, lookPath, run = oldGetenv, oldLookPath, oldRun })
+
+	var calls [][]string
+	getenv = func(k string) string { return env[k] }
+	lookPath = func(name string) (string, error) {
+		if slices.Contains(installed, name) {
+			return "/usr/bin/" + name, nil
+		}
+		return "", exec.ErrNotFound
+	}
+	run = func(_ context.Context, name string, args ...string) ([]byte, error) {
+		calls = append(calls, append([]string{name}, args...))
+		return []byte(outputs[strings.Join(args, " ")]), nil
+	}
+	return &calls
+}
+
+func TestReadImageWayland(t *testing.T) {
+	calls := fakeSession(t,
+		map[string]string{"WAYLAND_DISPLAY": "wayland-0"},
+		[]string{"wl-paste"},
+		map[string]string{
+			"--list-types":                  "text/plain\nimage/jpeg\nimage/png\n",
+			"--no-newline --type image/png": "PNGDATA",
+		})
+
+	data, err := ReadImage(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "PNGDATA" {
+		t.Fatalf("got %q", data)
+	}
+	if got := (*calls)[1]; !slices.Equal(got, []string{"wl-paste", "--no-newline", "--type", "image/png"}) {
+		t.Fatalf("unexpected read call %v", got)
+	}
+}
+
+func TestReadImageFallsBackToXclip(t *testing.T) {
+	calls := fakeSession(t,
+		map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"},
+		[]string{"xclip"},
+		map[string]string{
+			"-selection clipboard -t TARGETS -o":    "TARGETS\nimage/jpeg\n",
+			"-selection clipboard -t image/jpeg -o": "JPEGDATA",
+		})
+
+	data, err := ReadImage(context.Background())
+	if err != nil || string(data) != "JPEGDATA" {
+		t.Fatalf("got %q, %v", data, err)
+	}
+	if got := (*calls)[1]; got[0] != "xclip" || !slices.Contains(got, "image/jpeg") {
+		t.Fatalf("unexpected read call %v", got)
+	}
+}
+
+func TestReadImageErrors(t *testing.T) {
+	fakeSession(t, map[string]string{"DISPLAY": ":0"}, []string{"xclip"}, map[string]string{"-selection clipboard -t TARGETS -o": "UTF8_STRING\ntext/plain\n"})
+	if _, err := ReadImage(context.Background()); !errors.Is(err, ErrNoImage) {
+		t.Fatalf("expected ErrNoImage, got %v", err)
+	}
+
+	fakeSession(t, map[string]string{"DISPLAY": ":0"}, nil, nil)
+	if _, err := ReadImage(context.Background()); err == nil {
+		t.Fatal("expected error when no tool is installed")
+	}
+
+	fakeSession(t, nil, []string{"xclip"}, nil)
+	if _, err := ReadImage(context.Background()); err == nil {
+		t.Fatal("expected error without a display")
+	}
+}
@@ -9,19 +9,76 @@ import (
 // SetDefaults configures sensible defaults for the application.
 // Note: API key must be provided via SYN_API_KEY env var or config file.
 func SetDefaults() {
+	setDefaults(viper.GetViper())
+}
+
+func setDefaults(v *viper.Viper) {
 	// API defaults (key intentionally omitted - must be configured by user)
-	viper.SetDefault("api.base_url", "https://api.synthetic.new/openai/v1")
-	viper.SetDefault("api.anthropic_base_url", "https://api.synthetic.new/anthropic/v1")
-	viper.SetDefault("api.model", "hf:deepseek-ai/DeepSeek-V3.2")
-	viper.SetDefault("api.embedding_model", "hf:nomic-ai/nomic-embed-text-v1.5")
+	v.SetDefault("api.base_url", "https://api.synthetic.new/openai/v1")
+	v.SetDefault("api.anthropic_base_url", "https://api.synthetic.new/anthropic/v1")
+	v.SetDefault("api.model", "hf:deepseek-ai/DeepSeek-V3.2")
+	v.SetDefault("api.embedding_model", "hf:nomic-ai/nomic-embed-text-v1.5")
+	v.SetDefault("api.vision_model", "")
+
+	// Model capability catalog (empty = ~/.config/syn/models.yaml)
+	v.SetDefault("models.catalog_file", "")
 
 	// Retry configuration
-	viper.SetDefault("api.retry.max_attempts", 3)
-	viper.SetDefault("api.retry.initial_backoff", 1*time.Second)
-	viper.SetDefault("api.retry.max_backoff", 30*time.Second)
+	v.SetDefault("api.retry.max_attempts", 3)
+	v.SetDefault("api.retry.initial_backoff", 1*time.Second)
+	v.SetDefault("api.retry.max_backoff", 30*time.Second)
+
+	// Circuit breaker: fail fast after this many consecutive failed requests (0 disables)
+	v.SetDefault("api.circuit_breaker.threshold", 5)
+	v.SetDefault("api.circuit_breaker.cooldown", 30*time.Second)
+
+	// Client-side rate limits shared across processes (0 = unlimited; per-model
+	// overrides under ratelimit.models; empty state_file = ~/.config/syn/ratelimit.json)
+	v.SetDefault("ratelimit.rpm", 0)
+	v.SetDefault("ratelimit.tpm", 0)
+	v.SetDefault("ratelimit.state_file", "")
+
+	// OpenTelemetry spans and metrics for API requests (exporter: otlp or file;
+	// empty endpoint = OTEL_EXPORTER_OTLP_* env or localhost:4318; empty file =
+	// ~/.config/syn/telemetry.jsonl)
+	v.SetDefault("telemetry.enabled", false)
+	v.SetDefault("telemetry.exporter", "otlp")
+	v.SetDefault("telemetry.endpoint", "")
+	v.SetDefault("telemetry.headers", map[string]string{})
+	v.SetDefault("telemetry.file", "")
+	v.SetDefault("telemetry.service_name", "syn")
+	v.SetDefault("telemetry.metric_interval", 60*time.Second)
 
 	// Chat defaults
-	viper.SetDefault("chat.temperature", 0.6)
-	viper.SetDefault("chat.max_tokens", 8192)
-	viper.SetDefault("chat.top_p", 0.9)
+	v.SetDefault("chat.temperature", 0.6)
+	v.SetDefault("chat.max_tokens", 8192)
+	v.SetDefault("chat.top_p", 0.9)
+
+	// Response cache for chat and embeddings (opt-in; empty dir = user cache dir;
+	// max_bytes also caps the search cache)
+	v.SetDefault("cache.enabled", false)
+	v.SetDefault("cache.ttl", 7*24*time.Hour)
+	v.SetDefault("cache.max_bytes", int64(256<<20))
+	v.SetDefault("cache.dir", "")
+
+	// Search defaults (empty cache_dir = user cache dir, zero ttl disables caching)
+	v.SetDefault("search.cache_ttl", 1*time.Hour)
+	v.SetDefault("search.cache_dir", "")
+
+	// Index defaults (empty dir = ~/.config/syn/index)
+	v.SetDefault("index.dir", "")
+
+	// Retrieval-augmented chat
+	v.SetDefault("rag.top_k", 5)
+
+	// File context for -f (max_bytes per file keeps head and tail; binary: reject or hexdump)
+	v.SetDefault("files.max_bytes", 256<<10)
+	v.SetDefault("files.binary", "reject")
+	v.SetDefault("files.warn_tokens", 25000)
+
+	// Vision image preparation (format: jpeg, png or original; max_bytes is per request)
+	v.SetDefault("vision.max_dimension", 2048)
+	v.SetDefault("vision.quality", 85)
+	v.SetDefault("vision.format", "jpeg")
+	v.SetDefault("vision.max_bytes", 20<<20)
 }
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// DefaultPath returns the config file used when --config is not given,
+// ~/.config/syn/config.yaml.
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "syn", "config.yaml"), nil
+}
+
+// SetValue sets the value at keys (e.g. ["models", "aliases", "k2"]) in the
+// YAML file at path, creating the file and intermediate sections as needed.
+// Comments and the order of existing keys are preserved.
+func SetValue(path string, keys []string, value any) error {
+	if len(keys) == 0 {
+		return errors.New("empty config key")
+	}
+	doc, err := loadDocument(path)
+	if err != nil {
+		return err
+	}
+
+	node := doc.Content[0]
+	for i, key := range keys[:len(keys)-1] {
+		child := lookup(node, key)
+		if child == nil {
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, scalar(key), child)
+		}
+		if child.Kind != yaml.MappingNode {
+			return fmt.Errorf("%s: %s is not a section", path, strings.Join(keys[:i+1], "."))
+		}
+		node = child
+	}
+
+	var v yaml.Node
+	if err := v.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", strings.Join(keys, "."), err)
+	}
+	last := keys[len(keys)-1]
+	if existing := lookup(node, last); existing != nil {
+		*existing = v
+	} else {
+		node.Content = append(node.Content, scalar(last), &v)
+	}
+	return writeDocument(path, doc)
+}
+
+// DeleteValue removes the value at keys from the YAML file at path. It
+// reports whether the key was present.
+func DeleteValue(path string, keys []string) (bool, error) {
+	if len(keys) == 0 {
+		return false, errors.New("empty config key")
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	doc, err := loadDocument(path)
+	if err != nil {
+		return false, err
+	}
+
+	node := doc.Content[0]
+	for _, key := range keys[:len(keys)-1] {
+		if node = lookup(node, key); node == nil || node.Kind != yaml.MappingNode {
+			return false, nil
+		}
+	}
+	last := keys[len(keys)-1]
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == last {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return true, writeDocument(path, doc)
+		}
+	}
+	return false, nil
+}
+
+// KeyCase returns the keys of the mapping at section (e.g. "models.fallbacks")
+// in the YAML file at path as written, indexed by their lowercased form.
+// viper lowercases map keys, which breaks sections keyed by model IDs such as
+// hf:moonshotai/Kimi-K2.5; KeyCase recovers them. Keys under
+// profiles.<profile>.<section> are included. A missing file or section
+// yields an empty map.
+func KeyCase(path, section, profile string) (map[string]string, error) {
+	keys := map[string]string{}
+	if path == "" {
+		return keys, nil
+	}
+	doc, err := loadDocument(path)
+	if err != nil {
+		return nil, err
+	}
+	roots := []*yaml.Node{doc.Content[0]}
+	if profile != "" {
+		roots = append(roots, lookupFold(lookupFold(doc.Content[0], "profiles"), profile))
+	}
+	for _, node := range roots {
+		for part := range strings.SplitSeq(section, ".") {
+			node = lookupFold(node, part)
+		}
+		if node == nil || node.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			keys[strings.ToLower(key)] = key
+		}
+	}
+	return keys, nil
+}
+
+// loadDocument parses path into a document whose root is a mapping. A missing
+// or empty file yields an empty mapping.
+func loadDocument(path string) (*yaml.Node, error) {
+	doc := &yaml.Node{Kind: yaml.DocumentNode}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := yaml.Unmarshal(data, doc); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+	if len(doc.Content) == 0 {
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: top level is not a mapping", path)
+	}
+	return doc, nil
+}
+
+func writeDocument(path string, doc *yaml.Node) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	var b strings.Builder
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(b.String()), 0o600)
+}
+
+// lookup returns the value node for key in a mapping node.
+func lookup(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// lookupFold is lookup with keys matched case-insensitively, as viper does.
+// It returns nil for a nil or non-mapping node.
+func lookupFold(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if strings.EqualFold(m.Content[i].Value, key) {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func scalar(s string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+}
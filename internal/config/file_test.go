@@ -0,0 +1,95 @@
+package config
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetValuePreservesComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	orig := "# my settings\napi:\n  model: glm # preferred\n"
+	if err := os.WriteFile(path, []byte(orig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetValue(path, []string{"models", "aliases", "k2.5"}, "hf:moonshotai/Kimi-K2.5"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetValue(path, []string{"api", "model"}, "kimi"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(path)
+	got := string(data)
+	for _, want := range []string{"# my settings", "model: kimi", "aliases:", "k2.5: hf:moonshotai/Kimi-K2.5"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("missing %q in:\n%s", want, got)
+		}
+	}
+
+	if err := SetValue(path, []string{"api", "model", "x"}, "y"); err == nil {
+		t.Fatal("expected error setting below a scalar")
+	}
+}
+
+func TestDeleteValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "config.yaml")
+	if err := SetValue(path, []string{"models", "aliases", "k2"}, "hf:a/b"); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := DeleteValue(path, []string{"models", "aliases", "k2"})
+	if err != nil || !removed {
+		t.Fatalf("DeleteValue = %v, %v", removed, err)
+	}
+	removed, err = DeleteValue(path, []string{"models", "aliases", "k2"})
+	if err != nil || removed {
+		t.Fatalf("second DeleteValue = %v, %v", removed, err)
+	}
+	if removed, err := DeleteValue(filepath.Join(t.TempDir(), "none.yaml"), []string{"a"}); err != nil || removed {
+		t.Fatalf("DeleteValue(missing) = %v, %v", removed, err)
+	}
+}
+
+func TestKeyCase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := `ratelimit:
+  models:
+    hf:moonshotai/Kimi-K2.5: {rpm: 10}
+    glm: {rpm: 5}
+models:
+  fallbacks:
+    hf:MiniMaxAI/MiniMax-M2: [glm]
+profiles:
+  Work:
+    ratelimit:
+      models:
+        hf:Qwen/Qwen3-235B-A22B-Thinking-2507: {rpm: 2}
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := KeyCase(path, "ratelimit.models", "work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"hf:moonshotai/kimi-k2.5":               "hf:moonshotai/Kimi-K2.5",
+		"glm":                                   "glm",
+		"hf:qwen/qwen3-235b-a22b-thinking-2507": "hf:Qwen/Qwen3-235B-A22B-Thinking-2507",
+	}
+	if !maps.Equal(keys, want) {
+		t.Fatalf("KeyCase = %v", keys)
+	}
+	if keys, err := KeyCase(path, "models.fallbacks", ""); err != nil || keys["hf:minimaxai/minimax-m2"] != "hf:MiniMaxAI/MiniMax-M2" {
+		t.Fatalf("models.fallbacks = %v, %v", keys, err)
+	}
+
+	if keys, err := KeyCase(path, "models.aliases", ""); err != nil || len(keys) != 0 {
+		t.Fatalf("missing section = %v, %v", keys, err)
+	}
+}
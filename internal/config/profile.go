@@ -0,0 +1,33 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// ProfileNames returns the profiles defined under profiles, sorted.
+func ProfileNames(v *viper.Viper) []string {
+	return slices.Sorted(maps.Keys(v.GetStringMap("profiles")))
+}
+
+// ApplyProfile overlays profiles.<name> onto the loaded config file, so its
+// settings replace top-level ones while flags and environment variables still
+// take precedence. An empty name leaves the config unchanged.
+func ApplyProfile(v *viper.Viper, name string) error {
+	if name == "" {
+		return nil
+	}
+	settings, ok := v.Get("profiles." + strings.ToLower(name)).(map[string]any)
+	if !ok {
+		names := ProfileNames(v)
+		if len(names) == 0 {
+			return fmt.Errorf("unknown profile %q: no profiles are defined in the config file", name)
+		}
+		return fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(names, ", "))
+	}
+	return v.MergeConfigMap(settings)
+}
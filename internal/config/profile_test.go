@@ -0,0 +1,76 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const profileYAML = `
+api:
+  key: personal-key
+  model: deepseek
+  retry:
+    max_attempts: 3
+profiles:
+  work:
+    api:
+      key: work-key
+      base_url: https://work.example/v1
+      retry:
+        max_attempts: 5
+  local-vllm:
+    api:
+      base_url: http://localhost:8000/v1
+      model: qwen
+`
+
+func loadProfiles(t *testing.T) *viper.Viper {
+	t.Helper()
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(strings.NewReader(profileYAML)); err != nil {
+		t.Fatal(err)
+	}
+	v.SetDefault("api.retry.initial_backoff", time.Second)
+	return v
+}
+
+func TestApplyProfile(t *testing.T) {
+	v := loadProfiles(t)
+	if got := ProfileNames(v); strings.Join(got, ",") != "local-vllm,work" {
+		t.Fatalf("ProfileNames = %v", got)
+	}
+
+	if err := ApplyProfile(v, "Work"); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]any{
+		"api.key":                   "work-key",
+		"api.base_url":              "https://work.example/v1",
+		"api.model":                 "deepseek", // inherited
+		"api.retry.max_attempts":    5,
+		"api.retry.initial_backoff": time.Second, // default
+	} {
+		if got := v.Get(key); got != want {
+			t.Errorf("%s = %v, want %v", key, got, want)
+		}
+	}
+
+	v.Set("api.key", "flag-key") // flags and env outrank profiles
+	if err := ApplyProfile(v, "local-vllm"); err != nil || v.GetString("api.key") != "flag-key" {
+		t.Fatalf("api.key = %q, %v", v.GetString("api.key"), err)
+	}
+}
+
+func TestApplyUnknownProfile(t *testing.T) {
+	err := ApplyProfile(loadProfiles(t), "home")
+	if err == nil || !strings.Contains(err.Error(), "available: local-vllm, work") {
+		t.Fatalf("err = %v", err)
+	}
+	if err := ApplyProfile(viper.New(), ""); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+// Source is where an effective setting's value came from.
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceProfile Source = "profile"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// Setting is one effective configuration value.
+type Setting struct {
+	Key    string `json:"key"`
+	Value  any    `json:"value"`
+	Source Source `json:"source"`
+}
+
+// envAliases lists environment variables accepted for a key besides SYN_<KEY>.
+var envAliases = map[string][]string{ //nolint:gochecknoglobals // constant table
+	"api.key": {"SYN_API_KEY", "SYNTHETIC_API_KEY"},
+}
+
+// mapSections hold user-named entries, so any key below them is valid.
+var mapSections = []string{"models.aliases", "models.fallbacks", "ratelimit.models", "telemetry.headers", "profiles"} //nolint:gochecknoglobals // constant table
+
+// extraKeys are settings without a default value.
+var extraKeys = []string{"api.key", "profile"} //nolint:gochecknoglobals // constant table
+
+// BindEnv maps SYN_* environment variables onto v's keys, e.g.
+// SYN_API_RETRY_MAX_ATTEMPTS to api.retry.max_attempts.
+func BindEnv(v *viper.Viper) {
+	v.SetEnvPrefix("SYN")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	for key, names := range envAliases {
+		_ = v.BindEnv(append([]string{key}, names...)...)
+	}
+}
+
+// envVars returns the environment variables that set key.
+func envVars(key string) []string {
+	if names, ok := envAliases[key]; ok {
+		return names
+	}
+	return []string{"SYN_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))}
+}
+
+// defaults returns a viper holding only the built-in defaults.
+func defaults() *viper.Viper {
+	v := viper.New()
+	setDefaults(v)
+	return v
+}
+
+// Known reports whether key is a setting syn reads, including entries below
+// map sections such as models.aliases.<name> and profiles.<name>.<key>.
+func Known(key string) bool {
+	key = strings.ToLower(key)
+	if rest, ok := strings.CutPrefix(key, "profiles."); ok {
+		if _, sub, ok := strings.Cut(rest, "."); ok {
+			return Known(sub)
+		}
+		return true
+	}
+	for _, section := range mapSections {
+		if key == section || strings.HasPrefix(key, section+".") {
+			return true
+		}
+	}
+	return slices.Contains(extraKeys, key) || slices.Contains(defaults().AllKeys(), key)
+}
+
+// IsSecret reports whether key holds a credential that display output masks.
+func IsSecret(key string) bool {
+	last := key[strings.LastIndex(key, ".")+1:]
+	return last == "key" || strings.HasSuffix(last, "_key") || strings.Contains(last, "token") ||
+		strings.Contains(last, "secret") || strings.Contains(last, "password") ||
+		strings.HasPrefix(key, "telemetry.headers.")
+}
+
+// Mask hides all but the last four characters of secret values.
+func Mask(key string, value any) any {
+	s, ok := value.(string)
+	if !ok || s == "" || !IsSecret(key) {
+		return value
+	}
+	if len(s) <= 8 {
+		return "****"
+	}
+	return "****" + s[len(s)-4:]
+}
+
+// Effective returns every setting in v except profiles, sorted by key, with
+// the source of its value. flagSet reports keys whose bound flag was given;
+// profile is the active profile's name, or "".
+func Effective(v *viper.Viper, profile string, flagSet func(key string) bool) []Setting {
+	keys := v.AllKeys()
+	for _, key := range extraKeys {
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	settings := make([]Setting, 0, len(keys))
+	for _, key := range keys {
+		if key == "profiles" || strings.HasPrefix(key, "profiles.") {
+			continue
+		}
+		settings = append(settings, Lookup(v, key, profile, flagSet))
+	}
+	return settings
+}
+
+// Lookup returns key's effective value and source. Durations are rendered
+// as strings, such as 30s.
+func Lookup(v *viper.Viper, key, profile string, flagSet func(key string) bool) Setting {
+	key = strings.ToLower(key)
+	value := v.Get(key)
+	if _, ok := defaults().Get(key).(time.Duration); ok {
+		if d, err := cast.ToDurationE(value); err == nil {
+			value = d.String()
+		}
+	}
+	var overlay map[string]any
+	if profile != "" {
+		overlay, _ = v.Get("profiles." + strings.ToLower(profile)).(map[string]any)
+	}
+	return Setting{Key: key, Value: value, Source: source(v, key, overlay, flagSet)}
+}
+
+// source mirrors viper's precedence: flag, env, profile, file, default.
+func source(v *viper.Viper, key string, overlay map[string]any, flagSet func(string) bool) Source {
+	switch {
+	case flagSet != nil && flagSet(key):
+		return SourceFlag
+	case slices.ContainsFunc(envVars(key), func(name string) bool { return os.Getenv(name) != "" }):
+		return SourceEnv
+	case lookupPath(overlay, key):
+		return SourceProfile
+	case v.InConfig(key):
+		return SourceFile
+	}
+	return SourceDefault
+}
+
+// lookupPath reports whether the dotted key exists in a nested map.
+func lookupPath(m map[string]any, key string) bool {
+	for part := range strings.SplitSeq(key, ".") {
+		value, ok := m[part]
+		if !ok {
+			return false
+		}
+		if next, ok := value.(map[string]any); ok {
+			m = next
+		} else {
+			m = nil
+		}
+	}
+	return true
+}
+
+// ParseValue converts a command-line value to the YAML type it spells, so
+// "5" is stored as a number, "true" as a boolean and "[a, b]" as a list.
+func ParseValue(s string) (any, error) {
+	var v any
+	if err := yaml.Unmarshal([]byte(s), &v); err != nil {
+		return nil, fmt.Errorf("invalid value %q: %w", s, err)
+	}
+	if v == nil {
+		return s, nil
+	}
+	return v, nil
+}
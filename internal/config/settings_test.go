@@ -0,0 +1,85 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestEffectiveSources(t *testing.T) {
+	t.Setenv("SYN_API_RETRY_MAX_ATTEMPTS", "7")
+	t.Setenv("SYN_API_KEY", "")
+
+	v := viper.New()
+	setDefaults(v)
+	v.SetConfigType("yaml")
+	err := v.ReadConfig(strings.NewReader(`
+api:
+  key: syn_file_secret_1234
+  model: glm
+  retry:
+    max_backoff: 10s
+profiles:
+  work:
+    api:
+      model: kimi
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	BindEnv(v)
+	if err := ApplyProfile(v, "work"); err != nil {
+		t.Fatal(err)
+	}
+	v.Set("verbose", true) // stands in for a bound flag
+
+	got := map[string]Setting{}
+	for _, s := range Effective(v, "work", func(key string) bool { return key == "verbose" }) {
+		got[s.Key] = s
+	}
+	for key, want := range map[string]Setting{
+		"api.key":                   {Value: "syn_file_secret_1234", Source: SourceFile},
+		"api.model":                 {Value: "kimi", Source: SourceProfile},
+		"api.retry.max_attempts":    {Value: "7", Source: SourceEnv},
+		"api.retry.max_backoff":     {Value: "10s", Source: SourceFile},
+		"api.retry.initial_backoff": {Value: "1s", Source: SourceDefault},
+		"verbose":                   {Value: true, Source: SourceFlag},
+	} {
+		if s := got[key]; s.Value != want.Value || s.Source != want.Source {
+			t.Errorf("%s = %v (%s), want %v (%s)", key, s.Value, s.Source, want.Value, want.Source)
+		}
+	}
+	if _, ok := got["profiles.work.api.model"]; ok {
+		t.Error("profiles should not be listed")
+	}
+
+	if m := Mask("api.key", "syn_file_secret_1234"); m != "****1234" {
+		t.Errorf("Mask = %v", m)
+	}
+	if m := Mask("api.model", "kimi"); m != "kimi" {
+		t.Errorf("Mask(api.model) = %v", m)
+	}
+}
+
+func TestKnownAndParseValue(t *testing.T) {
+	for key, want := range map[string]bool{
+		"api.retry.max_attempts":       true,
+		"api.key":                      true,
+		"models.aliases.k2":            true,
+		"profiles.work.api.base_url":   true,
+		"profiles.work.api.base_ulr":   false,
+		"api.retry.max_attempt":        false,
+		"telemetry.headers.x-api-team": true,
+	} {
+		if Known(key) != want {
+			t.Errorf("Known(%q) = %v", key, !want)
+		}
+	}
+
+	for in, want := range map[string]any{"5": 5, "true": true, "30s": "30s", "": ""} {
+		if got, err := ParseValue(in); err != nil || got != want {
+			t.Errorf("ParseValue(%q) = %#v, %v", in, got, err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+// Problem levels.
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+)
+
+// Problem is a setting that fails validation.
+type Problem struct {
+	Key     string `json:"key"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+// urlKeys must be absolute http(s) URLs when set.
+var urlKeys = []string{"api.base_url", "api.anthropic_base_url", "telemetry.endpoint"} //nolint:gochecknoglobals // constant table
+
+// choices lists the accepted values of enumerated settings.
+var choices = map[string][]string{ //nolint:gochecknoglobals // constant table
+	"telemetry.exporter": {"otlp", "file"},
+	"files.binary":       {"reject", "hexdump"},
+	"vision.format":      {"jpeg", "png", "original"},
+}
+
+// ranges bounds numeric settings.
+var ranges = map[string][2]float64{ //nolint:gochecknoglobals // constant table
+	"chat.temperature": {0, 2},
+	"chat.top_p":       {0, 1},
+	"vision.quality":   {1, 100},
+}
+
+// Validate checks v's effective values offline: types against the defaults,
+// URLs, enumerations, numeric ranges, and keys in the config file that syn
+// does not read. Model names are checked by the caller against the API.
+func Validate(v *viper.Viper) []Problem {
+	var problems []Problem
+	add := func(key, level, format string, args ...any) {
+		problems = append(problems, Problem{Key: key, Level: level, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if v.GetString("api.key") == "" {
+		add("api.key", LevelError, "not set (use syn auth login, SYN_API_KEY or syn config set api.key <key>)")
+	}
+
+	def := defaults()
+	for _, key := range def.AllKeys() {
+		value := v.Get(key)
+		switch def.Get(key).(type) {
+		case time.Duration:
+			if d, err := cast.ToDurationE(value); err != nil {
+				add(key, LevelError, "%v is not a duration (use e.g. 500ms, 30s, 2h)", value)
+			} else if d < 0 {
+				add(key, LevelError, "must not be negative")
+			}
+		case int, int64:
+			if n, err := cast.ToInt64E(value); err != nil {
+				add(key, LevelError, "%v is not a whole number", value)
+			} else if n < 0 {
+				add(key, LevelError, "must not be negative")
+			}
+		case float64:
+			if _, err := cast.ToFloat64E(value); err != nil {
+				add(key, LevelError, "%v is not a number", value)
+			}
+		case bool:
+			if _, err := cast.ToBoolE(value); err != nil {
+				add(key, LevelError, "%v is not true or false", value)
+			}
+		}
+		if r, ok := ranges[key]; ok {
+			if n, err := cast.ToFloat64E(value); err == nil && (n < r[0] || n > r[1]) {
+				add(key, LevelError, "%v is outside %v-%v", n, r[0], r[1])
+			}
+		}
+	}
+
+	for _, key := range urlKeys {
+		if raw := v.GetString(key); raw != "" {
+			if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				add(key, LevelError, "%q is not an http(s) URL", raw)
+			}
+		}
+	}
+	for key, allowed := range choices {
+		if value := strings.ToLower(v.GetString(key)); !slices.Contains(allowed, value) {
+			add(key, LevelError, "%q is not one of %s", v.GetString(key), strings.Join(allowed, ", "))
+		}
+	}
+	if v.GetDuration("api.retry.initial_backoff") > v.GetDuration("api.retry.max_backoff") {
+		add("api.retry.initial_backoff", LevelWarning, "exceeds api.retry.max_backoff")
+	}
+
+	for _, key := range v.AllKeys() {
+		if v.InConfig(key) && !Known(key) {
+			add(key, LevelWarning, "unknown setting (typo?)")
+		}
+	}
+
+	slices.SortStableFunc(problems, func(a, b Problem) int { return strings.Compare(a.Key, b.Key) })
+	return problems
+}
+
+// CheckValue validates a single value about to be stored at key, which may
+// be below profiles.<name>. Only problems with key itself are returned.
+func CheckValue(key string, value any) []Problem {
+	key = strings.ToLower(key)
+	if rest, ok := strings.CutPrefix(key, "profiles."); ok {
+		if _, sub, ok := strings.Cut(rest, "."); ok {
+			key = sub
+		}
+	}
+	v := defaults()
+	v.Set("api.key", "unchecked")
+	v.Set(key, value)
+
+	var problems []Problem
+	for _, p := range Validate(v) {
+		if p.Key == key {
+			problems = append(problems, p)
+		}
+	}
+	return problems
+}
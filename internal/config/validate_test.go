@@ -0,0 +1,68 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestValidate(t *testing.T) {
+	v := viper.New()
+	setDefaults(v)
+	v.SetConfigType("yaml")
+	err := v.ReadConfig(strings.NewReader(`
+api:
+  key: k
+  base_url: api.synthetic.new/openai/v1
+  retry:
+    max_attempts: three
+    initial_backoff: 1 minute
+chat:
+  temperature: 3
+vision:
+  format: webp
+cahce:
+  enabled: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]string{}
+	for _, p := range Validate(v) {
+		got[p.Key] = p.Level
+	}
+	want := map[string]string{
+		"api.base_url":              LevelError,
+		"api.retry.max_attempts":    LevelError,
+		"api.retry.initial_backoff": LevelError,
+		"chat.temperature":          LevelError,
+		"vision.format":             LevelError,
+		"cahce.enabled":             LevelWarning,
+	}
+	for key, level := range want {
+		if got[key] != level {
+			t.Errorf("%s: level %q, want %q", key, got[key], level)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("problems = %v", got)
+	}
+
+	clean := viper.New()
+	setDefaults(clean)
+	clean.Set("api.key", "k")
+	if problems := Validate(clean); len(problems) != 0 {
+		t.Errorf("defaults: %+v", problems)
+	}
+}
+
+func TestCheckValue(t *testing.T) {
+	if problems := CheckValue("profiles.work.api.retry.max_backoff", "soon"); len(problems) != 1 || problems[0].Key != "api.retry.max_backoff" {
+		t.Errorf("profile duration: %+v", problems)
+	}
+	if problems := CheckValue("api.base_url", "https://example.com/v1"); len(problems) != 0 {
+		t.Errorf("valid URL: %+v", problems)
+	}
+}
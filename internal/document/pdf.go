@@ -0,0 +1,183 @@
+// Package document extracts prompt content from PDF files using the
+// poppler-utils tools (pdfinfo, pdftotext, pdftoppm). Pages with extractable
+// text become text context; scanned pages are rasterised to PNG so they can
+// be sent to a vision model.
+package document
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// RenderDPI is the resolution used to rasterise scanned pages.
+const RenderDPI = 150
+
+// MaxScannedPages bounds how many pages one PDF may rasterise.
+const MaxScannedPages = 20
+
+// minTextChars is the number of letters or digits below which a page is
+// treated as scanned.
+const minTextChars = 16
+
+// Seams for tests.
+var ( //nolint:gochecknoglobals // replaced in tests only
+	lookPath = exec.LookPath
+	run      = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		cmd := exec.CommandContext(ctx, name, args...) //nolint:gosec // G204: fixed tool names, path passed as argument
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil && stderr.Len() > 0 {
+			return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return out, err
+	}
+)
+
+// Page is one selected PDF page.
+type Page struct {
+	Number int
+	Text   string // extracted text; empty for scanned pages
+	Image  []byte // PNG rendering of a scanned page
+}
+
+// Scanned reports whether the page was rasterised instead of extracted.
+func (p Page) Scanned() bool {
+	return p.Image != nil
+}
+
+// IsPDF reports whether the file at path starts with the PDF magic bytes.
+func IsPDF(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	head := make([]byte, 5)
+	n, _ := f.Read(head)
+	return string(head[:n]) == "%PDF-"
+}
+
+// LoadPDF extracts the pages of path selected by spec (see ParsePages).
+func LoadPDF(ctx context.Context, path, spec string) ([]Page, error) {
+	for _, tool := range []string{"pdfinfo", "pdftotext", "pdftoppm"} {
+		if _, err := lookPath(tool); err != nil {
+			return nil, fmt.Errorf("%s not found: install poppler-utils to read PDFs", tool)
+		}
+	}
+
+	total, err := pageCount(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	numbers, err := ParsePages(spec, total)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	pages := make([]Page, 0, len(numbers))
+	scanned := 0
+	for _, n := range numbers {
+		num := strconv.Itoa(n)
+		out, err := run(ctx, "pdftotext", "-f", num, "-l", num, "-layout", "-enc", "UTF-8", path, "-")
+		if err != nil {
+			return nil, fmt.Errorf("pdftotext %s page %d: %w", path, n, err)
+		}
+		text := strings.TrimRight(string(out), "\f\n ")
+		if countAlnum(text) >= minTextChars {
+			pages = append(pages, Page{Number: n, Text: text})
+			continue
+		}
+
+		if scanned++; scanned > MaxScannedPages {
+			return nil, fmt.Errorf("%s: more than %d scanned pages selected; narrow the range with --pages", path, MaxScannedPages)
+		}
+		img, err := run(ctx, "pdftoppm", "-f", num, "-l", num, "-r", strconv.Itoa(RenderDPI), "-png", path)
+		if err != nil {
+			return nil, fmt.Errorf("pdftoppm %s page %d: %w", path, n, err)
+		}
+		pages = append(pages, Page{Number: n, Image: img})
+	}
+	return pages, nil
+}
+
+// pageCount reads the page count from pdfinfo.
+func pageCount(ctx context.Context, path string) (int, error) {
+	out, err := run(ctx, "pdfinfo", path)
+	if err != nil {
+		return 0, fmt.Errorf("pdfinfo %s: %w", path, err)
+	}
+	for line := range strings.SplitSeq(string(out), "\n") {
+		if rest, ok := strings.CutPrefix(line, "Pages:"); ok {
+			n, err := strconv.Atoi(strings.TrimSpace(rest))
+			if err != nil {
+				return 0, fmt.Errorf("pdfinfo %s: invalid page count %q", path, rest)
+			}
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("pdfinfo %s: page count not reported", path)
+}
+
+// ParsePages parses a page selection such as "1-5", "2,4,9-" or "" (all pages)
+// into ascending, de-duplicated 1-based page numbers within [1, total].
+// An open-ended range ("9-") runs to the last page.
+func ParsePages(spec string, total int) ([]int, error) {
+	if total <= 0 {
+		return nil, errors.New("document has no pages")
+	}
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		spec = "1-"
+	}
+
+	selected := make([]bool, total+1)
+	for part := range strings.SplitSeq(spec, ",") {
+		part = strings.TrimSpace(part)
+		lo, hi, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(strings.TrimSpace(lo))
+		if err != nil || first < 1 {
+			return nil, fmt.Errorf("invalid page selection %q", part)
+		}
+		last := first
+		if isRange {
+			last = total
+			if hi = strings.TrimSpace(hi); hi != "" {
+				if last, err = strconv.Atoi(hi); err != nil || last < first {
+					return nil, fmt.Errorf("invalid page range %q", part)
+				}
+			}
+		}
+		if first > total || last > total {
+			return nil, fmt.Errorf("page selection %q is outside the document (%d pages)", part, total)
+		}
+		for p := first; p <= last; p++ {
+			selected[p] = true
+		}
+	}
+
+	var pages []int
+	for p, ok := range selected {
+		if ok {
+			pages = append(pages, p)
+		}
+	}
+	return pages, nil
+}
+
+func countAlnum(s string) int {
+	n := 0
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,85 @@
+package document
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParsePages(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []int
+	}{
+		{"", []int{1, 2, 3, 4, 5, 6}},
+		{"1-3", []int{1, 2, 3}},
+		{"5,2, 2-3", []int{2, 3, 5}},
+		{"4-", []int{4, 5, 6}},
+	}
+	for _, tt := range tests {
+		got, err := ParsePages(tt.spec, 6)
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("ParsePages(%q) = %v, %v; want %v", tt.spec, got, err, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"0", "3-1", "7", "a-b", "1-9"} {
+		if _, err := ParsePages(bad, 6); err == nil {
+			t.Errorf("ParsePages(%q) expected error", bad)
+		}
+	}
+}
+
+func TestLoadPDFRoutesScannedPages(t *testing.T) {
+	oldLookPath, oldRun := lookPath, run
+	t.Cleanup(func() { lookPath, run = oldLookPath, oldRun })
+
+	lookPath = func(name string) (string, error) { return "/usr/bin/" + name, nil }
+	run = func(_ context.Context, name string, args ...string) ([]byte, error) {
+		switch name {
+		case "pdfinfo":
+			return []byte("Title: report\nPages:          3\n"), nil
+		case "pdftotext":
+			if args[1] == "2" {
+				return []byte("\f"), nil // scanned page: no text layer
+			}
+			return []byte("Quarterly revenue grew 12% year over year.\f"), nil
+		case "pdftoppm":
+			return []byte("PNG page " + args[1]), nil
+		}
+		t.Fatalf("unexpected tool %s", name)
+		return nil, nil
+	}
+
+	pages, err := LoadPDF(context.Background(), "report.pdf", "1-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 2 {
+		t.Fatalf("expected 2 pages, got %d", len(pages))
+	}
+	if pages[0].Scanned() || !strings.HasPrefix(pages[0].Text, "Quarterly") {
+		t.Fatalf("expected text page 1, got %+v", pages[0])
+	}
+	if !pages[1].Scanned() || string(pages[1].Image) != "PNG page 2" {
+		t.Fatalf("expected rasterised page 2, got %+v", pages[1])
+	}
+}
+
+func TestIsPDF(t *testing.T) {
+	dir := t.TempDir()
+	pdf := filepath.Join(dir, "a.pdf")
+	txt := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(pdf, []byte("%PDF-1.7\n..."), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(txt, []byte("hello"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if !IsPDF(pdf) || IsPDF(txt) || IsPDF(filepath.Join(dir, "missing")) {
+		t.Fatal("IsPDF misclassified files")
+	}
+}
@@ -53,6 +53,7 @@ type ModelResult struct {
 	Cases            []CaseResult `json:"cases"`
 	Summary          ModelSummary `json:"summary"`
 	ElapsedMS        int64        `json:"elapsed_ms"`
+	WaitMS           int64        `json:"wait_ms"` // retry backoff and rate-limit waits, excluded from tokens_per_sec
 	CompletionTokens int          `json:"completion_tokens"`
 	TokensPerSec     float64      `json:"tokens_per_sec"`
 	AvgTTFMS         int64        `json:"avg_ttf_ms"`
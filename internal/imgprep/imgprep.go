@@ -0,0 +1,174 @@
+// Package imgprep prepares images for upload to vision models: it applies
+// EXIF orientation, downscales to a maximum dimension and re-encodes, which
+// also strips metadata. Only standard library codecs are used, so WebP input
+// is passed through unchanged and output is JPEG or PNG.
+package imgprep
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // register GIF decoder
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+// Output formats.
+const (
+	FormatJPEG     = "jpeg"
+	FormatPNG      = "png"
+	FormatOriginal = "original" // send bytes unchanged
+)
+
+// MaxPixels bounds the decoded size of an image. Dimensions are read from
+// the header first, so a small file declaring a huge image is rejected before
+// its pixel buffer is allocated.
+const MaxPixels = 50_000_000
+
+// ErrTooManyPixels is returned for images larger than MaxPixels.
+var ErrTooManyPixels = errors.New("image dimensions too large")
+
+// Options controls how an image is prepared.
+type Options struct {
+	MaxDimension int    // longest side in pixels (0 = keep size)
+	Quality      int    // JPEG quality 1-100 (default 85)
+	Format       string // FormatJPEG (default), FormatPNG or FormatOriginal
+}
+
+// Result is a prepared image.
+type Result struct {
+	Data          []byte
+	MIMEType      string
+	Width, Height int  // output size; zero when the image was passed through
+	Processed     bool // false when the original bytes are returned
+}
+
+// Prepare decodes data, applies EXIF orientation, downscales it so neither
+// side exceeds opts.MaxDimension and re-encodes it in opts.Format. Formats the
+// standard library cannot decode (WebP) and FormatOriginal return data as-is.
+func Prepare(data []byte, opts Options) (Result, error) {
+	passthrough := Result{Data: data, MIMEType: http.DetectContentType(data)}
+
+	format := opts.Format
+	if format == "" {
+		format = FormatJPEG
+	}
+	switch format {
+	case FormatOriginal:
+		return passthrough, nil
+	case FormatJPEG, FormatPNG:
+	default:
+		return Result{}, fmt.Errorf("unknown image format %q (expected %s, %s or %s)", format, FormatJPEG, FormatPNG, FormatOriginal)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if errors.Is(err, image.ErrFormat) {
+		return passthrough, nil
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to decode image: %w", err)
+	}
+	if pixels := int64(cfg.Width) * int64(cfg.Height); pixels > MaxPixels {
+		return Result{}, fmt.Errorf("%w: %dx%d is %.1f megapixels (limit %d)",
+			ErrTooManyPixels, cfg.Width, cfg.Height, float64(pixels)/1e6, MaxPixels/1_000_000)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	img := Orient(src, exifOrientation(data))
+	img = Downscale(img, opts.MaxDimension)
+
+	var buf bytes.Buffer
+	res := Result{Width: img.Bounds().Dx(), Height: img.Bounds().Dy(), Processed: true}
+	if format == FormatPNG {
+		res.MIMEType = "image/png"
+		err = png.Encode(&buf, img)
+	} else {
+		res.MIMEType = "image/jpeg"
+		err = jpeg.Encode(&buf, flatten(img), &jpeg.Options{Quality: quality(opts.Quality)})
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to encode image: %w", err)
+	}
+	res.Data = buf.Bytes()
+	return res, nil
+}
+
+func quality(q int) int {
+	if q <= 0 {
+		return 85
+	}
+	return min(q, 100)
+}
+
+// flatten composites img onto white, since JPEG has no alpha channel.
+func flatten(img image.Image) image.Image {
+	if o, ok := img.(interface{ Opaque() bool }); ok && o.Opaque() {
+		return img
+	}
+	out := image.NewRGBA(img.Bounds())
+	draw.Draw(out, out.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(out, out.Bounds(), img, img.Bounds().Min, draw.Over)
+	return out
+}
+
+// Downscale shrinks img so its longest side is at most maxDim, averaging the
+// source pixels covered by each output pixel (box filter). Images that already
+// fit, or maxDim <= 0, are returned unchanged.
+func Downscale(img image.Image, maxDim int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if maxDim <= 0 || (w <= maxDim && h <= maxDim) {
+		return img
+	}
+
+	dw, dh := maxDim, max(1, h*maxDim/w)
+	if h > w {
+		dw, dh = max(1, w*maxDim/h), maxDim
+	}
+
+	src := toRGBA(img)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for dy := range dh {
+		y0, y1 := dy*h/dh, max((dy+1)*h/dh, dy*h/dh+1)
+		for dx := range dw {
+			x0, x1 := dx*w/dw, max((dx+1)*w/dw, dx*w/dw+1)
+			var r, g, bl, a, n uint64
+			for y := y0; y < y1; y++ {
+				row := src.Pix[y*src.Stride:]
+				for x := x0; x < x1; x++ {
+					p := row[x*4 : x*4+4]
+					r += uint64(p[0])
+					g += uint64(p[1])
+					bl += uint64(p[2])
+					a += uint64(p[3])
+					n++
+				}
+			}
+			o := dst.PixOffset(dx, dy)
+			dst.Pix[o+0] = uint8(r / n)  //nolint:gosec // G115: average of uint8 values
+			dst.Pix[o+1] = uint8(g / n)  //nolint:gosec // G115: average of uint8 values
+			dst.Pix[o+2] = uint8(bl / n) //nolint:gosec // G115: average of uint8 values
+			dst.Pix[o+3] = uint8(a / n)  //nolint:gosec // G115: average of uint8 values
+		}
+	}
+	return dst
+}
+
+// toRGBA returns img as a zero-origin *image.RGBA (premultiplied alpha).
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	b := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(out, out.Bounds(), img, b.Min, draw.Src)
+	return out
+}
@@ -0,0 +1,138 @@
+package imgprep
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func solid(w, h int, c color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// withOrientation inserts an EXIF APP1 segment carrying orientation o after
+// the SOI marker of a JPEG.
+func withOrientation(jpg []byte, o uint16) []byte {
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	tiff = binary.LittleEndian.AppendUint16(tiff, 1)      // one IFD entry
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0x0112) // orientation
+	tiff = binary.LittleEndian.AppendUint16(tiff, 3)      // SHORT
+	tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, o)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0) // value padding + next IFD
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	seg := []byte{0xFF, 0xE1}
+	seg = binary.BigEndian.AppendUint16(seg, uint16(len(payload)+2)) //nolint:gosec // G115: small test segment
+	seg = append(seg, payload...)
+
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, seg...)
+	return append(out, jpg[2:]...)
+}
+
+func TestPrepareDownscalesAndFlattens(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, solid(400, 200, color.NRGBA{R: 255, A: 0})); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Prepare(buf.Bytes(), Options{MaxDimension: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.MIMEType != "image/jpeg" || res.Width != 100 || res.Height != 50 || !res.Processed {
+		t.Fatalf("unexpected result: %s %dx%d processed=%v", res.MIMEType, res.Width, res.Height, res.Processed)
+	}
+
+	out, err := jpeg.Decode(bytes.NewReader(res.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, g, b, _ := out.At(50, 25).RGBA(); r>>8 < 240 || g>>8 < 240 || b>>8 < 240 {
+		t.Fatalf("expected transparent pixels flattened to white, got %v", out.At(50, 25))
+	}
+}
+
+func TestPrepareAppliesOrientation(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, solid(40, 20, color.Gray{Y: 128}), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	data := withOrientation(buf.Bytes(), 6)
+	if o := exifOrientation(data); o != 6 {
+		t.Fatalf("exifOrientation = %d, want 6", o)
+	}
+
+	res, err := Prepare(data, Options{Format: FormatPNG})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.MIMEType != "image/png" || res.Width != 20 || res.Height != 40 {
+		t.Fatalf("expected rotated 20x40 png, got %s %dx%d", res.MIMEType, res.Width, res.Height)
+	}
+	if bytes.Contains(res.Data, []byte("Exif")) {
+		t.Fatal("expected EXIF to be stripped")
+	}
+}
+
+func TestOrientRotatesClockwise(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.Set(0, 0, color.RGBA{R: 255, A: 255})
+	src.Set(1, 0, color.RGBA{B: 255, A: 255})
+
+	out := Orient(src, 6)
+	if b := out.Bounds(); b.Dx() != 1 || b.Dy() != 2 {
+		t.Fatalf("expected 1x2, got %v", b)
+	}
+	if r, _, _, _ := out.At(0, 0).RGBA(); r == 0 {
+		t.Fatal("expected left pixel at the top after rotation")
+	}
+	if _, _, b, _ := out.At(0, 1).RGBA(); b == 0 {
+		t.Fatal("expected right pixel at the bottom after rotation")
+	}
+}
+
+func TestPreparePassthrough(t *testing.T) {
+	webp := []byte("RIFF\x1a\x00\x00\x00WEBPVP8 \x0e\x00\x00\x00")
+	res, err := Prepare(webp, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Processed || res.MIMEType != "image/webp" || !bytes.Equal(res.Data, webp) {
+		t.Fatalf("expected WebP passthrough, got %+v", res)
+	}
+
+	if _, err := Prepare(webp, Options{Format: "webp"}); err == nil {
+		t.Fatal("expected error for unsupported output format")
+	}
+}
+
+func TestPrepareRejectsHugeDimensions(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, solid(1, 1, color.White)); err != nil {
+		t.Fatal(err)
+	}
+	// Rewrite the IHDR chunk to declare 20000x20000 pixels.
+	data := buf.Bytes()
+	binary.BigEndian.PutUint32(data[16:], 20000)
+	binary.BigEndian.PutUint32(data[20:], 20000)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+
+	if _, err := Prepare(data, Options{}); !errors.Is(err, ErrTooManyPixels) {
+		t.Fatalf("Prepare = %v, want ErrTooManyPixels", err)
+	}
+}
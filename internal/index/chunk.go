@@ -0,0 +1,96 @@
+package index
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// TextChunk is a slice of a document with its 1-based line span.
+type TextChunk struct {
+	Text      string
+	StartLine int
+	EndLine   int
+}
+
+// ChunkText splits text into chunks of roughly size characters on line
+// boundaries, repeating about overlap characters of trailing lines at the start
+// of the next chunk, as far as the next line still fits. Lines longer than size
+// are split hard, on rune boundaries.
+func ChunkText(text string, size, overlap int) []TextChunk {
+	if size <= 0 {
+		size = 1500
+	}
+	overlap = max(min(overlap, size/2), 0)
+
+	type line struct {
+		text string
+		num  int
+	}
+
+	var lines []line
+	for i, l := range strings.Split(text, "\n") {
+		for len(l) > size {
+			n := runeCut(l, size)
+			lines = append(lines, line{text: l[:n], num: i + 1})
+			l = l[n:]
+		}
+		lines = append(lines, line{text: l, num: i + 1})
+	}
+
+	var chunks []TextChunk
+	var current []line
+	length := 0
+
+	flush := func() {
+		var b strings.Builder
+		for i, l := range current {
+			if i > 0 {
+				b.WriteByte('\n')
+			}
+			b.WriteString(l.text)
+		}
+		if body := strings.TrimSpace(b.String()); body != "" {
+			chunks = append(chunks, TextChunk{
+				Text:      body,
+				StartLine: current[0].num,
+				EndLine:   current[len(current)-1].num,
+			})
+		}
+	}
+
+	for _, l := range lines {
+		if length > 0 && length+len(l.text)+1 > size {
+			flush()
+
+			// Carry trailing lines forward as overlap, leaving room for l.
+			limit := min(overlap, size-len(l.text)-1)
+			carried := 0
+			start := len(current)
+			for start > 0 && carried+len(current[start-1].text)+1 <= limit {
+				start--
+				carried += len(current[start].text) + 1
+			}
+			current = append([]line(nil), current[start:]...)
+			length = carried
+		}
+		current = append(current, l)
+		length += len(l.text) + 1
+	}
+	if len(current) > 0 {
+		flush()
+	}
+
+	return chunks
+}
+
+// runeCut returns the largest cut point in s at most n bytes in that does not
+// split a UTF-8 sequence, or the end of the first rune if that is longer.
+func runeCut(s string, n int) int {
+	for i := n; i > 0; i-- {
+		if utf8.RuneStart(s[i]) {
+			return i
+		}
+	}
+	_, size := utf8.DecodeRuneInString(s)
+	return size
+}
@@ -0,0 +1,277 @@
+package index
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dotcommander/syn/internal/app"
+	"github.com/dotcommander/syn/internal/vector"
+)
+
+// Index is a local, file-backed collection of embedded text chunks.
+type Index struct {
+	Name       string    `json:"name"`
+	Model      string    `json:"model"`
+	Dimensions int       `json:"dimensions"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	Chunks     []Chunk   `json:"chunks"`
+}
+
+// Chunk is one embedded piece of a source document.
+type Chunk struct {
+	ID        string    `json:"id"`
+	Source    string    `json:"source"`
+	StartLine int       `json:"start_line"`
+	EndLine   int       `json:"end_line"`
+	Text      string    `json:"text"`
+	Vector    []float32 `json:"vector"`
+}
+
+// Hit is a query match with its cosine similarity score.
+type Hit struct {
+	Chunk Chunk   `json:"chunk"`
+	Score float64 `json:"score"`
+}
+
+// Summary describes a stored index without loading its vectors into callers.
+type Summary struct {
+	Name      string    `json:"name"`
+	Model     string    `json:"model"`
+	Chunks    int       `json:"chunks"`
+	Sources   int       `json:"sources"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Path      string    `json:"path"`
+}
+
+var validName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`) //nolint:gochecknoglobals // compiled regex
+
+// DefaultDir returns the default index directory (~/.config/syn/index).
+func DefaultDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "syn", "index"), nil
+}
+
+// Path returns the file path of the named index in dir.
+func Path(dir, name string) (string, error) {
+	if !validName.MatchString(name) {
+		return "", fmt.Errorf("invalid index name %q (use letters, digits, '.', '_' or '-')", name)
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// New returns an empty index bound to an embedding model.
+func New(name, model string) *Index {
+	now := time.Now()
+	return &Index{Name: name, Model: model, CreatedAt: now, UpdatedAt: now}
+}
+
+// Load reads the named index from dir.
+func Load(dir, name string) (*Index, error) {
+	path, err := Path(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("index %q not found in %s", name, dir)
+		}
+		return nil, fmt.Errorf("read index: %w", err)
+	}
+	var ix Index
+	if err := json.Unmarshal(data, &ix); err != nil {
+		return nil, fmt.Errorf("parse index %s: %w", path, err)
+	}
+	return &ix, nil
+}
+
+// Save writes the index to dir, replacing any previous version.
+func (ix *Index) Save(dir string) error {
+	path, err := Path(dir, ix.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create index dir: %w", err)
+	}
+
+	data, err := json.Marshal(ix)
+	if err != nil {
+		return fmt.Errorf("marshal index: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write index: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("commit index: %w", err)
+	}
+	return nil
+}
+
+// List returns summaries of all indexes in dir, sorted by name.
+func List(dir string) ([]Summary, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read index dir: %w", err)
+	}
+
+	var out []Summary
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".json")
+		if e.IsDir() || !ok {
+			continue
+		}
+		ix, err := Load(dir, name)
+		if err != nil {
+			continue
+		}
+		out = append(out, ix.Summary(filepath.Join(dir, e.Name())))
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// Remove deletes the named index from dir.
+func Remove(dir, name string) error {
+	path, err := Path(dir, name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("index %q not found in %s", name, dir)
+		}
+		return fmt.Errorf("remove index: %w", err)
+	}
+	return nil
+}
+
+// Summary returns counts and metadata for the index.
+func (ix *Index) Summary(path string) Summary {
+	return Summary{
+		Name:      ix.Name,
+		Model:     ix.Model,
+		Chunks:    len(ix.Chunks),
+		Sources:   len(ix.Sources()),
+		UpdatedAt: ix.UpdatedAt,
+		Path:      path,
+	}
+}
+
+// Sources returns the distinct chunk sources, sorted.
+func (ix *Index) Sources() []string {
+	seen := map[string]struct{}{}
+	for _, c := range ix.Chunks {
+		seen[c.Source] = struct{}{}
+	}
+	out := make([]string, 0, len(seen))
+	for s := range seen {
+		out = append(out, s)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Replace swaps all chunks belonging to source for the given ones.
+// Re-adding a file therefore updates it instead of duplicating it.
+func (ix *Index) Replace(source string, chunks []Chunk) error {
+	for _, c := range chunks {
+		if ix.Dimensions == 0 {
+			ix.Dimensions = len(c.Vector)
+		}
+		if len(c.Vector) != ix.Dimensions {
+			return fmt.Errorf("chunk %s has %d dimensions, index %q expects %d", c.ID, len(c.Vector), ix.Name, ix.Dimensions)
+		}
+	}
+
+	kept := ix.Chunks[:0]
+	for _, c := range ix.Chunks {
+		if c.Source != source {
+			kept = append(kept, c)
+		}
+	}
+	ix.Chunks = append(kept, chunks...)
+	ix.UpdatedAt = time.Now()
+	return nil
+}
+
+// Query returns the top k chunks by cosine similarity to vec.
+func (ix *Index) Query(vec []float32, k int) []Hit {
+	hits := make([]Hit, 0, len(ix.Chunks))
+	for _, c := range ix.Chunks {
+		hits = append(hits, Hit{Chunk: c, Score: vector.Cosine(vec, c.Vector)})
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	if k > 0 && len(hits) > k {
+		hits = hits[:k]
+	}
+	return hits
+}
+
+// EmbedChunks fills in chunk vectors with client.EmbedBatch, which batches
+// requests, runs them concurrently and retries them. A chunk longer than
+// opts.MaxChunkTokens is embedded in pieces whose vectors are averaged.
+func EmbedChunks(ctx context.Context, client app.BatchEmbeddingClient, opts app.EmbedBatchOptions, chunks []Chunk) error {
+	texts := make([]string, len(chunks))
+	for i, c := range chunks {
+		texts[i] = c.Text
+	}
+
+	res, err := client.EmbedBatch(ctx, texts, opts)
+	if err != nil {
+		return err
+	}
+
+	sums := make([][]float64, len(chunks))
+	counts := make([]int, len(chunks))
+	for _, ec := range res.Chunks {
+		if ec.Input < 0 || ec.Input >= len(chunks) {
+			return fmt.Errorf("embedding for input %d out of range", ec.Input)
+		}
+		if sums[ec.Input] == nil {
+			sums[ec.Input] = make([]float64, len(ec.Embedding))
+		}
+		if len(ec.Embedding) != len(sums[ec.Input]) {
+			return fmt.Errorf("chunk %s: pieces have different dimensions", chunks[ec.Input].ID)
+		}
+		for k, x := range ec.Embedding {
+			sums[ec.Input][k] += x
+		}
+		counts[ec.Input]++
+	}
+	for i, sum := range sums {
+		if counts[i] == 0 {
+			return fmt.Errorf("no embedding for chunk %s", chunks[i].ID)
+		}
+		for k := range sum {
+			sum[k] /= float64(counts[i])
+		}
+		chunks[i].Vector = vector.FromFloat64(sum)
+	}
+	return nil
+}
+
+// Location formats a chunk's source and line span, e.g. "docs/run.md:10-42".
+func (c Chunk) Location() string {
+	if c.StartLine == c.EndLine {
+		return fmt.Sprintf("%s:%d", c.Source, c.StartLine)
+	}
+	return fmt.Sprintf("%s:%d-%d", c.Source, c.StartLine, c.EndLine)
+}
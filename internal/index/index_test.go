@@ -0,0 +1,139 @@
+package index
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/dotcommander/syn/internal/app"
+)
+
+type fakeEmbedder struct {
+	calls int
+	opts  app.EmbedBatchOptions
+}
+
+// EmbedBatch splits texts like the real pipeline and returns a 2-d vector per
+// piece: (len(piece), 1).
+func (f *fakeEmbedder) EmbedBatch(_ context.Context, texts []string, opts app.EmbedBatchOptions) (*app.EmbedBatchResult, error) {
+	f.calls++
+	f.opts = opts
+	res := &app.EmbedBatchResult{Model: opts.Model}
+	for i, text := range texts {
+		for j, piece := range app.SplitByTokens(text, opts.MaxChunkTokens, 0) {
+			res.Chunks = append(res.Chunks, app.EmbeddedChunk{Input: i, Chunk: j, Text: piece, Embedding: []float64{float64(len(piece)), 1}})
+		}
+	}
+	return res, nil
+}
+
+func TestChunkText(t *testing.T) {
+	text := strings.Repeat("line of text\n", 100)
+	chunks := ChunkText(text, 130, 26)
+
+	if len(chunks) < 2 {
+		t.Fatalf("expected multiple chunks, got %d", len(chunks))
+	}
+	for _, c := range chunks {
+		if len(c.Text) > 130 {
+			t.Fatalf("chunk exceeds size: %d", len(c.Text))
+		}
+	}
+	if chunks[1].StartLine > chunks[0].EndLine {
+		t.Fatalf("expected overlap: chunk 2 starts at %d, chunk 1 ends at %d", chunks[1].StartLine, chunks[0].EndLine)
+	}
+	if chunks[0].StartLine != 1 {
+		t.Fatalf("expected first chunk to start at line 1, got %d", chunks[0].StartLine)
+	}
+}
+
+func TestChunkTextSplitsLongLines(t *testing.T) {
+	chunks := ChunkText(strings.Repeat("x", 250), 100, 0)
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d", len(chunks))
+	}
+}
+
+func TestChunkTextKeepsRunesAndSize(t *testing.T) {
+	text := strings.Repeat("Größenänderung über Straßen — 日本語のテキスト\n", 20) + strings.Repeat("ü", 300)
+	for _, overlap := range []int{0, 40} {
+		for _, c := range ChunkText(text, 97, overlap) {
+			if !utf8.ValidString(c.Text) {
+				t.Fatalf("overlap %d: chunk is not valid UTF-8: %q", overlap, c.Text)
+			}
+			if len(c.Text) > 97 {
+				t.Fatalf("overlap %d: chunk exceeds size: %d", overlap, len(c.Text))
+			}
+		}
+	}
+}
+
+func TestEmbedReplaceQuery(t *testing.T) {
+	chunks := []Chunk{
+		{ID: "a#1", Source: "a", Text: "short"},
+		{ID: "a#2", Source: "a", Text: "a much longer chunk of text"},
+		{ID: "a#3", Source: "a", Text: "mid-sized text"},
+	}
+
+	emb := &fakeEmbedder{}
+	if err := EmbedChunks(context.Background(), emb, app.EmbedBatchOptions{Model: "m", BatchSize: 2}, chunks); err != nil {
+		t.Fatalf("EmbedChunks() error = %v", err)
+	}
+	if emb.calls != 1 || emb.opts.BatchSize != 2 {
+		t.Fatalf("expected one EmbedBatch call with the batch size, got %d calls, %+v", emb.calls, emb.opts)
+	}
+	if chunks[1].Vector[0] != float32(len(chunks[1].Text)) {
+		t.Fatalf("vector not assigned to its chunk: %v", chunks[1].Vector)
+	}
+
+	ix := New("test", "m")
+	if err := ix.Replace("a", chunks); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+
+	hits := ix.Query([]float32{1, 0}, 1)
+	if len(hits) != 1 || hits[0].Chunk.ID != "a#2" {
+		t.Fatalf("expected longest chunk as top hit, got %+v", hits)
+	}
+
+	if err := ix.Replace("a", chunks[:1]); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+	if len(ix.Chunks) != 1 {
+		t.Fatalf("expected re-add to replace source chunks, got %d", len(ix.Chunks))
+	}
+}
+
+func TestEmbedChunksAveragesSplitChunks(t *testing.T) {
+	chunks := []Chunk{{ID: "a#1", Source: "a", Text: strings.Repeat("word ", 40)}}
+	if err := EmbedChunks(context.Background(), &fakeEmbedder{}, app.EmbedBatchOptions{MaxChunkTokens: 20}, chunks); err != nil {
+		t.Fatal(err)
+	}
+	if v := chunks[0].Vector; len(v) != 2 || v[1] != 1 || v[0] >= float32(len(chunks[0].Text)) {
+		t.Fatalf("expected the mean of the piece vectors, got %v", v)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	ix := New("docs", "m")
+	if err := ix.Replace("f", []Chunk{{ID: "f#1", Source: "f", Vector: []float32{1, 2}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ix.Save(dir); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(dir, "docs")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(loaded.Chunks) != 1 || loaded.Dimensions != 2 {
+		t.Fatalf("unexpected loaded index: %+v", loaded)
+	}
+
+	if _, err := Path(dir, "../escape"); err == nil {
+		t.Fatalf("expected invalid name error")
+	}
+}
@@ -0,0 +1,132 @@
+package keyring
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"filippo.io/age"
+)
+
+// File stores each account's secret in <Dir>/<account>.age, encrypted with
+// an age scrypt passphrase recipient. It is the fallback where no keyring
+// is available.
+type File struct {
+	Dir        string                 // directory holding the .age files
+	Passphrase func() (string, error) // asked on every Get and Set
+	WorkFactor int                    // scrypt log2 work factor (0 = age's default)
+}
+
+// DefaultDir returns the default directory, ~/.config/syn/credentials.
+func DefaultDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "credentials"
+	}
+	return filepath.Join(home, ".config", "syn", "credentials")
+}
+
+// NewFile returns a store in dir that asks passphrase for the key.
+func NewFile(dir string, passphrase func() (string, error)) *File {
+	return &File{Dir: dir, Passphrase: passphrase}
+}
+
+// Name implements Store.
+func (f *File) Name() string { return NameFile }
+
+// Path returns the file holding account's secret.
+func (f *File) Path(account string) string {
+	return filepath.Join(f.Dir, account+".age")
+}
+
+// Exists reports whether a secret is stored for account, without asking for
+// the passphrase.
+func (f *File) Exists(account string) bool {
+	_, err := os.Stat(f.Path(account))
+	return err == nil
+}
+
+// Get implements Store.
+func (f *File) Get(account string) (string, error) {
+	data, err := os.ReadFile(f.Path(account))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	pass, err := f.Passphrase()
+	if err != nil {
+		return "", err
+	}
+	identity, err := age.NewScryptIdentity(pass)
+	if err != nil {
+		return "", err
+	}
+	r, err := age.Decrypt(bytes.NewReader(data), identity)
+	if err != nil {
+		return "", fmt.Errorf("decrypt %s (wrong passphrase?): %w", f.Path(account), err)
+	}
+	secret, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("decrypt %s: %w", f.Path(account), err)
+	}
+	return string(secret), nil
+}
+
+// Set implements Store. The file is written to a temporary name and renamed,
+// so a failed write leaves the previous secret intact.
+func (f *File) Set(account, secret string) error {
+	pass, err := f.Passphrase()
+	if err != nil {
+		return err
+	}
+	recipient, err := age.NewScryptRecipient(pass)
+	if err != nil {
+		return err
+	}
+	if f.WorkFactor > 0 {
+		recipient.SetWorkFactor(f.WorkFactor)
+	}
+
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipient)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, secret); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(f.Dir, 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(f.Dir, account+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // best-effort cleanup; gone after rename
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close() //nolint:errcheck,gosec // write error takes precedence
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.Path(account))
+}
+
+// Delete implements Store.
+func (f *File) Delete(account string) error {
+	err := os.Remove(f.Path(account))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
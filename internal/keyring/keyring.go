@@ -0,0 +1,48 @@
+// Package keyring stores API keys outside config.yaml: in the Secret Service
+// keyring through secret-tool, or in an age passphrase-encrypted file.
+package keyring
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Store names reported by Store.Name.
+const (
+	NameKeyring = "keyring"
+	NameFile    = "encrypted-file"
+	NameMemory  = "memory"
+)
+
+// ErrNotFound is returned when a store holds no secret for an account.
+var ErrNotFound = errors.New("no stored API key")
+
+// Store holds one secret per account. syn uses the profile name as the
+// account, or "default" when no profile is active.
+type Store interface {
+	Name() string
+	Get(account string) (string, error)
+	Set(account, secret string) error
+	Delete(account string) error
+}
+
+// Lookup returns the secret for account from the first store that has one,
+// trying stores in order. A store that fails is skipped; its error is
+// returned only if no later store has the secret. With no secret anywhere
+// the error is ErrNotFound.
+func Lookup(account string, stores ...Store) (string, Store, error) {
+	var errs []error
+	for _, s := range stores {
+		secret, err := s.Get(account)
+		switch {
+		case err == nil:
+			return secret, s, nil
+		case !errors.Is(err, ErrNotFound):
+			errs = append(errs, fmt.Errorf("%s: %w", s.Name(), err))
+		}
+	}
+	if len(errs) > 0 {
+		return "", nil, errors.Join(errs...)
+	}
+	return "", nil, ErrNotFound
+}
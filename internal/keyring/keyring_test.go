@@ -0,0 +1,146 @@
+package keyring
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLookupOrder(t *testing.T) {
+	first, second := NewMemory(), NewMemory()
+	if _, _, err := Lookup("work", first, second); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("empty stores: %v", err)
+	}
+
+	if err := second.Set("work", "sk-second"); err != nil {
+		t.Fatal(err)
+	}
+	secret, from, err := Lookup("work", first, second)
+	if err != nil || secret != "sk-second" || from != second {
+		t.Fatalf("Lookup = %q, %v, %v", secret, from, err)
+	}
+
+	// A failing keyring falls through to the next store.
+	first.Err = errors.New("keyring locked")
+	if secret, _, err := Lookup("work", first, second); err != nil || secret != "sk-second" {
+		t.Fatalf("fallthrough = %q, %v", secret, err)
+	}
+	if _, _, err := Lookup("home", first, second); err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("store error should be reported when nothing is found: %v", err)
+	}
+
+	first.Err = nil
+	if err := second.Delete("work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.Delete("work"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("second Delete = %v", err)
+	}
+}
+
+func TestFileRoundTrip(t *testing.T) {
+	pass := "correct horse"
+	f := NewFile(filepath.Join(t.TempDir(), "credentials"), func() (string, error) { return pass, nil })
+	f.WorkFactor = 10
+
+	if _, err := f.Get("default"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get before Set = %v", err)
+	}
+	if err := f.Set("default", "sk-file-secret"); err != nil {
+		t.Fatal(err)
+	}
+	if !f.Exists("default") {
+		t.Fatal("Exists = false after Set")
+	}
+	info, err := os.Stat(f.Path("default"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	data, err := os.ReadFile(f.Path("default"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) == "sk-file-secret" || len(data) < 100 {
+		t.Fatalf("file is not encrypted: %q", data)
+	}
+
+	if got, err := f.Get("default"); err != nil || got != "sk-file-secret" {
+		t.Fatalf("Get = %q, %v", got, err)
+	}
+	pass = "wrong"
+	if _, err := f.Get("default"); err == nil {
+		t.Fatal("Get with the wrong passphrase succeeded")
+	}
+
+	if err := f.Delete("default"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Delete("default"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("second Delete = %v", err)
+	}
+}
+
+// fakeSecretTool mimics secret-tool's lookup, store and clear with one file
+// per account.
+const fakeSecretTool = `#!/bin/sh
+dir="$(dirname "$0")/items"
+mkdir -p "$dir"
+case "$1" in
+lookup) [ -f "$dir/$5" ] || exit 1; cat "$dir/$5" ;;
+store) cat > "$dir/$7" ;;
+clear) rm -f "$dir/$5" ;;
+esac
+`
+
+func TestSecretService(t *testing.T) {
+	bin := filepath.Join(t.TempDir(), "secret-tool")
+	if err := os.WriteFile(bin, []byte(fakeSecretTool), 0o700); err != nil { //nolint:gosec // test script must be executable
+		t.Fatal(err)
+	}
+	s := &SecretService{Command: bin}
+	if !s.Available() {
+		t.Fatal("Available = false")
+	}
+
+	if _, err := s.Get("work"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get before Set = %v", err)
+	}
+	if err := s.Set("work", "sk-keyring"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.Get("work"); err != nil || got != "sk-keyring" {
+		t.Fatalf("Get = %q, %v", got, err)
+	}
+	if err := s.Delete("work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("work"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("second Delete = %v", err)
+	}
+
+	if (&SecretService{Command: "syn-no-such-secret-tool"}).Available() {
+		t.Error("Available = true for a missing binary")
+	}
+}
+
+func TestSecretServiceLookupTimeout(t *testing.T) {
+	bin := filepath.Join(t.TempDir(), "secret-tool")
+	if err := os.WriteFile(bin, []byte("#!/bin/sh\nsleep 10\n"), 0o700); err != nil { //nolint:gosec // test script must be executable
+		t.Fatal(err)
+	}
+	s := &SecretService{Command: bin, Timeout: 100 * time.Millisecond}
+
+	start := time.Now()
+	_, err := s.Get("work")
+	if err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get = %v, want a timeout error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Get took %s", elapsed)
+	}
+}
@@ -0,0 +1,59 @@
+package keyring
+
+import "sync"
+
+// Memory is an in-process Store for tests. Err, when set, is returned by
+// every call, standing in for a locked or unreachable keyring.
+type Memory struct {
+	Err error
+
+	mu      sync.Mutex
+	secrets map[string]string
+}
+
+// NewMemory returns an empty Memory store.
+func NewMemory() *Memory {
+	return &Memory{secrets: map[string]string{}}
+}
+
+// Name implements Store.
+func (m *Memory) Name() string { return NameMemory }
+
+// Get implements Store.
+func (m *Memory) Get(account string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return "", m.Err
+	}
+	secret, ok := m.secrets[account]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+// Set implements Store.
+func (m *Memory) Set(account, secret string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	m.secrets[account] = secret
+	return nil
+}
+
+// Delete implements Store.
+func (m *Memory) Delete(account string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	if _, ok := m.secrets[account]; !ok {
+		return ErrNotFound
+	}
+	delete(m.secrets, account)
+	return nil
+}
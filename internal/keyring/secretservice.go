@@ -0,0 +1,100 @@
+package keyring
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// service is the Secret Service attribute shared by all syn entries.
+const service = "syn"
+
+// defaultLookupTimeout bounds a lookup, which runs before most commands, so a
+// stuck D-Bus or unlock prompt fails the lookup instead of hanging syn.
+const defaultLookupTimeout = 5 * time.Second
+
+// SecretService stores secrets in the desktop keyring (GNOME Keyring,
+// KWallet) through libsecret's secret-tool, under the attributes
+// service=syn and account=<account>.
+type SecretService struct {
+	Command string        // secret-tool binary (default: "secret-tool" from PATH)
+	Timeout time.Duration // limit for a lookup (default: 5s); store and clear may wait on an unlock prompt
+}
+
+// NewSecretService returns a store backed by secret-tool.
+func NewSecretService() *SecretService {
+	return &SecretService{Command: "secret-tool", Timeout: defaultLookupTimeout}
+}
+
+// Name implements Store.
+func (s *SecretService) Name() string { return NameKeyring }
+
+// Available reports whether secret-tool is installed.
+func (s *SecretService) Available() bool {
+	_, err := exec.LookPath(s.Command)
+	return err == nil
+}
+
+// Get implements Store. secret-tool exits 1 without output when no item
+// matches, which is reported as ErrNotFound.
+func (s *SecretService) Get(account string) (string, error) {
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = defaultLookupTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	out, err := s.run(ctx, "", "lookup", "service", service, "account", account)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("secret-tool lookup: no answer within %s", timeout)
+		}
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && len(out) == 0 {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	if len(out) == 0 {
+		return "", ErrNotFound
+	}
+	return string(out), nil
+}
+
+// Set implements Store, replacing any existing secret for account.
+func (s *SecretService) Set(account, secret string) error {
+	_, err := s.run(context.Background(), secret, "store", "--label", "syn API key ("+account+")", "service", service, "account", account)
+	return err
+}
+
+// Delete implements Store. It returns ErrNotFound when nothing was stored,
+// since secret-tool clear succeeds either way.
+func (s *SecretService) Delete(account string) error {
+	if _, err := s.Get(account); err != nil {
+		return err
+	}
+	_, err := s.run(context.Background(), "", "clear", "service", service, "account", account)
+	return err
+}
+
+// run executes secret-tool with stdin and returns its stdout. Errors carry
+// secret-tool's stderr, which explains D-Bus and unlock failures. secret-tool
+// is killed when ctx is done.
+func (s *SecretService) run(ctx context.Context, stdin string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, s.Command, args...) //nolint:gosec // fixed binary, arguments are not shell-interpreted
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.WaitDelay = time.Second // don't wait on children still holding stdout
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return stdout.Bytes(), fmt.Errorf("secret-tool %s: %s: %w", args[0], msg, err)
+		}
+		return stdout.Bytes(), fmt.Errorf("secret-tool %s: %w", args[0], err)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,21 @@
+//go:build !unix
+
+package ratelimit
+
+import (
+	"os"
+	"sync"
+)
+
+// Without flock the state file is only locked within this process; parallel
+// processes may overdraw the budget.
+var stateMu sync.Mutex //nolint:gochecknoglobals // process-wide file lock
+
+func lockFile(*os.File) error {
+	stateMu.Lock()
+	return nil
+}
+
+func unlockFile(*os.File) {
+	stateMu.Unlock()
+}
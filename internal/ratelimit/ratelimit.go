@@ -0,0 +1,202 @@
+// Package ratelimit implements per-model token buckets for requests and
+// tokens per minute. Bucket state lives in a file locked for each update, so
+// every syn process sharing the file draws from the same budget.
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Limit is a per-minute budget. Zero fields are unlimited.
+type Limit struct {
+	RequestsPerMinute int `mapstructure:"rpm" json:"rpm,omitempty"`
+	TokensPerMinute   int `mapstructure:"tpm" json:"tpm,omitempty"`
+}
+
+// Unlimited reports whether l sets no budget.
+func (l Limit) Unlimited() bool {
+	return l.RequestsPerMinute <= 0 && l.TokensPerMinute <= 0
+}
+
+// bucket is the persisted state of one model's budget. Tokens may go
+// negative when a response uses more than was reserved.
+type bucket struct {
+	Requests float64   `json:"requests"`
+	Tokens   float64   `json:"tokens"`
+	Updated  time.Time `json:"updated"`
+}
+
+// Limiter applies a Limit per model, falling back to a default Limit for
+// models without one.
+type Limiter struct {
+	path     string
+	defaults Limit
+	models   map[string]Limit
+	now      func() time.Time
+}
+
+// New returns a limiter whose shared state lives at path. models maps model
+// IDs to limits; other models use defaults. New returns nil when no limit is
+// set, and a nil Limiter never waits.
+func New(path string, defaults Limit, models map[string]Limit) *Limiter {
+	if defaults.Unlimited() {
+		limited := false
+		for _, l := range models {
+			limited = limited || !l.Unlimited()
+		}
+		if !limited {
+			return nil
+		}
+	}
+	return &Limiter{path: path, defaults: defaults, models: models, now: time.Now}
+}
+
+// DefaultPath returns the shared state file, ~/.config/syn/ratelimit.json.
+func DefaultPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "syn", "ratelimit.json")
+}
+
+// Limit returns the limit applied to model.
+func (l *Limiter) Limit(model string) Limit {
+	if lim, ok := l.models[model]; ok {
+		return lim
+	}
+	return l.defaults
+}
+
+// Wait blocks until model has budget for one request of tokens tokens, then
+// takes it, and returns how long it waited. Reservations larger than the
+// per-minute budget wait for a full bucket rather than forever.
+func (l *Limiter) Wait(ctx context.Context, model string, tokens int) (time.Duration, error) {
+	if l == nil {
+		return 0, nil
+	}
+	lim := l.Limit(model)
+	if lim.Unlimited() {
+		return 0, nil
+	}
+
+	var waited time.Duration
+	for {
+		var wait time.Duration
+		err := l.update(func(state map[string]*bucket) {
+			b := l.refill(state, model, lim)
+			need := min(float64(tokens), float64(lim.TokensPerMinute))
+			wait = max(deficit(b.Requests, 1, lim.RequestsPerMinute), deficit(b.Tokens, need, lim.TokensPerMinute))
+			if wait > 0 {
+				return
+			}
+			if lim.RequestsPerMinute > 0 {
+				b.Requests--
+			}
+			if lim.TokensPerMinute > 0 {
+				b.Tokens -= float64(tokens)
+			}
+		})
+		if err != nil || wait <= 0 {
+			return waited, err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+			waited += wait
+		case <-ctx.Done():
+			timer.Stop()
+			return waited, ctx.Err()
+		}
+	}
+}
+
+// Charge adjusts model's token bucket by tokens once real usage is known;
+// negative values refund an over-reservation.
+func (l *Limiter) Charge(model string, tokens int) error {
+	if l == nil || tokens == 0 {
+		return nil
+	}
+	lim := l.Limit(model)
+	if lim.TokensPerMinute <= 0 {
+		return nil
+	}
+	return l.update(func(state map[string]*bucket) {
+		b := l.refill(state, model, lim)
+		b.Tokens = min(b.Tokens-float64(tokens), float64(lim.TokensPerMinute))
+	})
+}
+
+// refill returns model's bucket topped up for the time since its last update.
+func (l *Limiter) refill(state map[string]*bucket, model string, lim Limit) *bucket {
+	now := l.now()
+	b := state[model]
+	if b == nil {
+		b = &bucket{Requests: float64(lim.RequestsPerMinute), Tokens: float64(lim.TokensPerMinute), Updated: now}
+		state[model] = b
+	}
+	minutes := now.Sub(b.Updated).Minutes()
+	if minutes > 0 {
+		b.Requests = min(b.Requests+minutes*float64(lim.RequestsPerMinute), float64(lim.RequestsPerMinute))
+		b.Tokens = min(b.Tokens+minutes*float64(lim.TokensPerMinute), float64(lim.TokensPerMinute))
+		b.Updated = now
+	}
+	return b
+}
+
+// deficit returns how long a bucket refilling perMinute units per minute
+// takes to hold need units. A non-positive perMinute means unlimited.
+func deficit(have, need float64, perMinute int) time.Duration {
+	if perMinute <= 0 || have >= need {
+		return 0
+	}
+	return time.Duration((need - have) / float64(perMinute) * float64(time.Minute))
+}
+
+// update applies fn to the bucket state under an exclusive lock on the state
+// file, then writes the state back.
+func (l *Limiter) update(fn func(map[string]*bucket)) error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return fmt.Errorf("create rate limit dir: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("open rate limit state: %w", err)
+	}
+	defer f.Close()
+
+	if err := lockFile(f); err != nil {
+		return fmt.Errorf("lock rate limit state: %w", err)
+	}
+	defer unlockFile(f)
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return fmt.Errorf("read rate limit state: %w", err)
+	}
+	state := make(map[string]*bucket)
+	if len(data) > 0 && json.Unmarshal(data, &state) != nil {
+		state = make(map[string]*bucket) // corrupt state: start with full buckets
+	}
+
+	fn(state)
+
+	out, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("marshal rate limit state: %w", err)
+	}
+	if err := f.Truncate(0); err != nil {
+		return fmt.Errorf("write rate limit state: %w", err)
+	}
+	if _, err := f.WriteAt(out, 0); err != nil {
+		return fmt.Errorf("write rate limit state: %w", err)
+	}
+	return nil
+}
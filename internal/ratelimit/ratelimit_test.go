@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blocked reports whether Wait would block, by giving it a short deadline.
+func blocked(t *testing.T, l *Limiter, model string, tokens int) bool {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := l.Wait(ctx, model, tokens)
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal(err)
+	}
+	return err != nil
+}
+
+func TestRequestsPerMinuteSharedByPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratelimit.json")
+	now := time.Now()
+	clock := func() time.Time { return now }
+
+	a := New(path, Limit{RequestsPerMinute: 2}, nil)
+	b := New(path, Limit{RequestsPerMinute: 2}, nil) // another process
+	a.now, b.now = clock, clock
+
+	if blocked(t, a, "m", 0) || blocked(t, b, "m", 0) {
+		t.Fatal("first two requests should not wait")
+	}
+	if !blocked(t, a, "m", 0) {
+		t.Fatal("third request should wait for the shared bucket")
+	}
+	if blocked(t, b, "other", 0) {
+		t.Fatal("buckets are per model")
+	}
+
+	now = now.Add(30 * time.Second) // refills one request at 2 rpm
+	if blocked(t, b, "m", 0) || !blocked(t, a, "m", 0) {
+		t.Fatal("expected exactly one refilled request")
+	}
+}
+
+func TestTokensPerMinuteAndCharge(t *testing.T) {
+	l := New(filepath.Join(t.TempDir(), "rl.json"), Limit{}, map[string]Limit{"m": {TokensPerMinute: 1000}})
+	now := time.Now()
+	l.now = func() time.Time { return now }
+
+	if blocked(t, l, "m", 100) {
+		t.Fatal("reservation within budget should not wait")
+	}
+	if err := l.Charge("m", 800); err != nil { // the response used 900 tokens
+		t.Fatal(err)
+	}
+	if !blocked(t, l, "m", 200) {
+		t.Fatal("expected to wait after usage was charged")
+	}
+	if blocked(t, l, "unlimited", 1<<20) {
+		t.Fatal("models without a limit should not wait")
+	}
+
+	now = now.Add(time.Minute)
+	if blocked(t, l, "m", 5000) {
+		t.Fatal("oversized reservation should go through on a full bucket")
+	}
+}
+
+func TestConcurrentWaitersNeverOverdraw(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rl.json")
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	var granted atomic.Int32
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Go(func() {
+			l := New(path, Limit{RequestsPerMinute: 5}, nil)
+			if _, err := l.Wait(ctx, "m", 0); err == nil {
+				granted.Add(1)
+			}
+		})
+	}
+	wg.Wait()
+	if got := granted.Load(); got != 5 {
+		t.Fatalf("granted %d requests, want 5", got)
+	}
+}
+
+func TestNewWithoutLimits(t *testing.T) {
+	if l := New("unused", Limit{}, map[string]Limit{"m": {}}); l != nil {
+		t.Fatal("expected nil limiter")
+	}
+	var l *Limiter
+	if _, err := l.Wait(context.Background(), "m", 10); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,193 @@
+package search
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dotcommander/syn/internal/app"
+)
+
+// Filter holds client-side constraints applied to search results.
+type Filter struct {
+	Sites   []string  // keep only results hosted on these domains (subdomains included)
+	Exclude []string  // drop results whose title, URL, or snippet contains any term
+	Since   time.Time // drop results published before this time (zero = no limit)
+	Limit   int       // maximum results to return (0 = no limit)
+}
+
+// trackingParams are query parameters stripped during URL canonicalization.
+var trackingParams = []string{"utm_", "fbclid", "gclid", "ref", "ref_src"} //nolint:gochecknoglobals // read-only lookup table
+
+// Apply de-duplicates results by canonical URL, then applies the filter in order:
+// site, exclude, since, limit. Results without a parseable Published date are
+// dropped when Since is set.
+func Apply(results []app.SearchResult, f Filter) []app.SearchResult {
+	deduped := Dedupe(results)
+
+	filtered := make([]app.SearchResult, 0, len(deduped))
+	for _, r := range deduped {
+		if !matchesSites(r.URL, f.Sites) || matchesExclude(r, f.Exclude) {
+			continue
+		}
+		if !f.Since.IsZero() {
+			published, ok := parsePublished(r.Published)
+			if !ok || published.Before(f.Since) {
+				continue
+			}
+		}
+		filtered = append(filtered, r)
+	}
+
+	if f.Limit > 0 && len(filtered) > f.Limit {
+		filtered = filtered[:f.Limit]
+	}
+	return filtered
+}
+
+// Dedupe removes results that share a canonical URL, keeping the first occurrence.
+func Dedupe(results []app.SearchResult) []app.SearchResult {
+	seen := make(map[string]struct{}, len(results))
+	out := make([]app.SearchResult, 0, len(results))
+	for _, r := range results {
+		key := CanonicalURL(r.URL)
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, r)
+	}
+	return out
+}
+
+// CanonicalURL normalizes a URL for duplicate detection: lowercases the host,
+// strips "www.", the scheme, fragments, tracking parameters and trailing slashes,
+// and sorts the remaining query parameters.
+func CanonicalURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return strings.ToLower(strings.TrimSpace(raw))
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+
+	query := u.Query()
+	for param := range query {
+		if isTrackingParam(param) {
+			query.Del(param)
+		}
+	}
+
+	path := strings.TrimRight(u.EscapedPath(), "/")
+	canonical := host + path
+	if len(query) > 0 {
+		keys := make([]string, 0, len(query))
+		for k := range query {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(keys))
+		for _, k := range keys {
+			for _, v := range query[k] {
+				parts = append(parts, url.QueryEscape(k)+"="+url.QueryEscape(v))
+			}
+		}
+		canonical += "?" + strings.Join(parts, "&")
+	}
+	return canonical
+}
+
+// ParseSince parses a --since value relative to now. Accepted forms are
+// durations ("36h", "90m"), day/week shorthands ("7d", "2w"), dates
+// ("2025-01-31") and RFC 3339 timestamps. Relative values must be positive,
+// since a zero or negative one would put the cutoff at or after now.
+func ParseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if n, unit := s[:len(s)-1], s[len(s)-1]; unit == 'd' || unit == 'w' {
+		if count, err := strconv.Atoi(n); err == nil {
+			if count <= 0 {
+				return time.Time{}, fmt.Errorf("invalid --since %q: must be positive", s)
+			}
+			days := count
+			if unit == 'w' {
+				days *= 7
+			}
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+
+	if d, err := time.ParseDuration(s); err == nil {
+		if d <= 0 {
+			return time.Time{}, fmt.Errorf("invalid --since %q: must be positive", s)
+		}
+		return now.Add(-d), nil
+	}
+	if t, ok := parsePublished(s); ok {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid --since %q (use e.g. 7d, 2w, 36h, 2025-01-31)", s)
+}
+
+func isTrackingParam(param string) bool {
+	p := strings.ToLower(param)
+	for _, t := range trackingParams {
+		if p == t || (strings.HasSuffix(t, "_") && strings.HasPrefix(p, t)) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesSites(raw string, sites []string) bool {
+	if len(sites) == 0 {
+		return true
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	for _, site := range sites {
+		site = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(site)), "www.")
+		if site == "" {
+			continue
+		}
+		if host == site || strings.HasSuffix(host, "."+site) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesExclude(r app.SearchResult, terms []string) bool {
+	haystack := strings.ToLower(r.Title + "\n" + r.URL + "\n" + r.Snippet)
+	for _, term := range terms {
+		term = strings.ToLower(strings.TrimSpace(term))
+		if term != "" && strings.Contains(haystack, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePublished parses the ISO 8601 forms seen in SearchResult.Published.
+func parsePublished(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,105 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dotcommander/syn/internal/app"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"https://www.Example.com/docs/", "http://example.com/docs"},
+		{"https://example.com/a?utm_source=x&b=2&a=1#frag", "https://example.com/a?a=1&b=2"},
+		{"https://example.com:443/a", "https://example.com/a"},
+	}
+
+	for _, tc := range tests {
+		if got, want := CanonicalURL(tc.a), CanonicalURL(tc.b); got != want {
+			t.Fatalf("CanonicalURL(%q) = %q, want %q", tc.a, got, want)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	results := []app.SearchResult{
+		{Title: "Go generics", URL: "https://go.dev/doc/generics", Published: "2025-06-01T00:00:00Z"},
+		{Title: "Go generics (dup)", URL: "https://www.go.dev/doc/generics/?utm_source=feed"},
+		{Title: "Generics blog", URL: "https://blog.go.dev/generics", Published: "2024-01-01"},
+		{Title: "Reddit thread", URL: "https://reddit.com/r/golang/1", Published: "2025-07-01"},
+		{Title: "Undated", URL: "https://go.dev/undated"},
+	}
+
+	t.Run("dedupe", func(t *testing.T) {
+		got := Apply(results, Filter{})
+		if len(got) != 4 {
+			t.Fatalf("expected 4 results after dedupe, got %d", len(got))
+		}
+		if got[0].Title != "Go generics" {
+			t.Fatalf("expected first occurrence kept, got %q", got[0].Title)
+		}
+	})
+
+	t.Run("site-includes-subdomains", func(t *testing.T) {
+		got := Apply(results, Filter{Sites: []string{"go.dev"}})
+		if len(got) != 3 {
+			t.Fatalf("expected 3 go.dev results, got %d", len(got))
+		}
+	})
+
+	t.Run("exclude", func(t *testing.T) {
+		got := Apply(results, Filter{Exclude: []string{"REDDIT"}})
+		for _, r := range got {
+			if r.Title == "Reddit thread" {
+				t.Fatalf("expected reddit result excluded")
+			}
+		}
+	})
+
+	t.Run("since-drops-old-and-undated", func(t *testing.T) {
+		since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		got := Apply(results, Filter{Since: since})
+		if len(got) != 2 {
+			t.Fatalf("expected 2 results since 2025, got %d", len(got))
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		got := Apply(results, Filter{Limit: 1})
+		if len(got) != 1 {
+			t.Fatalf("expected 1 result, got %d", len(got))
+		}
+	})
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"7d", now.AddDate(0, 0, -7)},
+		{"2w", now.AddDate(0, 0, -14)},
+		{"36h", now.Add(-36 * time.Hour)},
+		{"2025-01-31", time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{"", time.Time{}},
+	}
+
+	for _, tc := range tests {
+		got, err := ParseSince(tc.in, now)
+		if err != nil {
+			t.Fatalf("ParseSince(%q) error = %v", tc.in, err)
+		}
+		if !got.Equal(tc.want) {
+			t.Fatalf("ParseSince(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+
+	for _, in := range []string{"yesterday", "-2h", "0s", "-3d", "0w"} {
+		if _, err := ParseSince(in, now); err == nil {
+			t.Fatalf("ParseSince(%q): expected error", in)
+		}
+	}
+}
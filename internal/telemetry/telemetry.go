@@ -0,0 +1,153 @@
+// Package telemetry builds OpenTelemetry trace and meter providers for the
+// CLI, exporting over OTLP/HTTP or to a local JSON-lines file.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Exporter names accepted in Config.Exporter.
+const (
+	ExporterOTLP = "otlp"
+	ExporterFile = "file"
+)
+
+// Config selects where spans and metrics go.
+type Config struct {
+	Exporter       string            // "otlp" or "file"
+	Endpoint       string            // OTLP base URL, e.g. http://localhost:4318 ("" = OTEL_EXPORTER_OTLP_* env or SDK default)
+	Headers        map[string]string // extra OTLP request headers, such as auth tokens
+	File           string            // file exporter path ("" = DefaultPath())
+	ServiceName    string            // service.name resource attribute (default: syn)
+	MetricInterval time.Duration     // metric export period (default: 60s; metrics are also flushed on Shutdown)
+}
+
+// Providers holds the SDK providers built by Setup.
+type Providers struct {
+	Tracer *sdktrace.TracerProvider
+	Meter  *sdkmetric.MeterProvider
+	file   io.Closer
+}
+
+// DefaultPath returns the default file exporter path, ~/.config/syn/telemetry.jsonl.
+func DefaultPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "telemetry.jsonl"
+	}
+	return filepath.Join(home, ".config", "syn", "telemetry.jsonl")
+}
+
+// Setup builds trace and meter providers exporting as cfg describes.
+// Callers must call Shutdown to flush buffered spans and metrics.
+func Setup(ctx context.Context, cfg Config) (*Providers, error) {
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = "syn"
+	}
+	if cfg.MetricInterval <= 0 {
+		cfg.MetricInterval = time.Minute
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attribute.String("service.name", cfg.ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("telemetry resource: %w", err)
+	}
+
+	p := &Providers{}
+	var spans sdktrace.SpanExporter
+	var metrics sdkmetric.Exporter
+	switch cfg.Exporter {
+	case ExporterOTLP, "":
+		spans, metrics, err = otlpExporters(ctx, cfg)
+	case ExporterFile:
+		spans, metrics, p.file, err = fileExporters(cfg.File)
+	default:
+		return nil, fmt.Errorf("unknown telemetry exporter %q (want %s or %s)", cfg.Exporter, ExporterOTLP, ExporterFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.Tracer = sdktrace.NewTracerProvider(sdktrace.WithBatcher(spans), sdktrace.WithResource(res))
+	p.Meter = sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metrics, sdkmetric.WithInterval(cfg.MetricInterval))),
+		sdkmetric.WithResource(res))
+	return p, nil
+}
+
+func otlpExporters(ctx context.Context, cfg Config) (sdktrace.SpanExporter, sdkmetric.Exporter, error) {
+	var traceOpts []otlptracehttp.Option
+	var metricOpts []otlpmetrichttp.Option
+	if cfg.Endpoint != "" {
+		base := strings.TrimRight(cfg.Endpoint, "/")
+		traceOpts = append(traceOpts, otlptracehttp.WithEndpointURL(base+"/v1/traces"))
+		metricOpts = append(metricOpts, otlpmetrichttp.WithEndpointURL(base+"/v1/metrics"))
+	}
+	if len(cfg.Headers) > 0 {
+		traceOpts = append(traceOpts, otlptracehttp.WithHeaders(cfg.Headers))
+		metricOpts = append(metricOpts, otlpmetrichttp.WithHeaders(cfg.Headers))
+	}
+
+	spans, err := otlptracehttp.New(ctx, traceOpts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("otlp trace exporter: %w", err)
+	}
+	metrics, err := otlpmetrichttp.New(ctx, metricOpts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("otlp metric exporter: %w", err)
+	}
+	return spans, metrics, nil
+}
+
+// fileExporters appends spans and metrics to path, one JSON object per line.
+func fileExporters(path string) (sdktrace.SpanExporter, sdkmetric.Exporter, io.Closer, error) {
+	if path == "" {
+		path = DefaultPath()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, nil, nil, fmt.Errorf("create telemetry dir: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("open telemetry file: %w", err)
+	}
+
+	spans, err := stdouttrace.New(stdouttrace.WithWriter(f))
+	if err != nil {
+		f.Close()
+		return nil, nil, nil, fmt.Errorf("file trace exporter: %w", err)
+	}
+	metrics, err := stdoutmetric.New(stdoutmetric.WithWriter(f))
+	if err != nil {
+		f.Close()
+		return nil, nil, nil, fmt.Errorf("file metric exporter: %w", err)
+	}
+	return spans, metrics, f, nil
+}
+
+// Shutdown flushes and stops both providers and closes the export file.
+func (p *Providers) Shutdown(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	err := errors.Join(p.Tracer.Shutdown(ctx), p.Meter.Shutdown(ctx))
+	if p.file != nil {
+		err = errors.Join(err, p.file.Close())
+	}
+	return err
+}
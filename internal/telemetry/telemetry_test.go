@@ -0,0 +1,78 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "telemetry.jsonl")
+	p, err := Setup(context.Background(), Config{Exporter: ExporterFile, File: path, ServiceName: "test-svc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, span := p.Tracer.Tracer("test").Start(context.Background(), "chat m")
+	span.End()
+	counter, err := p.Meter.Meter("test").Int64Counter("syn.client.token.usage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counter.Add(context.Background(), 3)
+
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"Name":"chat m"`, "syn.client.token.usage", "test-svc"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("export file lacks %s:\n%s", want, data)
+		}
+	}
+}
+
+func TestOTLPExporterPaths(t *testing.T) {
+	var mu sync.Mutex
+	got := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		got[r.URL.Path] = r.Header.Get("X-Team")
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	p, err := Setup(context.Background(), Config{Endpoint: srv.URL + "/", Headers: map[string]string{"X-Team": "ml"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, span := p.Tracer.Tracer("test").Start(context.Background(), "chat m")
+	span.End()
+	counter, _ := p.Meter.Meter("test").Int64Counter("c")
+	counter.Add(context.Background(), 1)
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, path := range []string{"/v1/traces", "/v1/metrics"} {
+		if team, ok := got[path]; !ok || team != "ml" {
+			t.Errorf("%s: received %v, header %q", path, ok, team)
+		}
+	}
+}
+
+func TestUnknownExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), Config{Exporter: "zipkin"}); err == nil {
+		t.Fatal("expected error for unknown exporter")
+	}
+}
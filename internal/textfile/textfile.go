@@ -0,0 +1,241 @@
+// Package textfile reads files for use as prompt context: it caps size with
+// head/tail truncation, rejects or summarises binary files, and transcodes
+// UTF-16 and Latin-1 text to UTF-8.
+package textfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Binary file handling modes.
+const (
+	BinaryReject  = "reject"  // return ErrBinary
+	BinaryHexdump = "hexdump" // return a size/type summary and a short hexdump
+)
+
+// Encodings reported in Result.Encoding.
+const (
+	EncodingUTF8    = "utf-8"
+	EncodingUTF16LE = "utf-16le"
+	EncodingUTF16BE = "utf-16be"
+	EncodingLatin1  = "latin-1"
+	EncodingBinary  = "binary"
+)
+
+// ErrBinary is returned for binary files when Options.Binary is BinaryReject.
+var ErrBinary = errors.New("binary file")
+
+const (
+	sniffLen    = 8192
+	hexdumpLen  = 256
+	headPercent = 67 // share of MaxBytes kept from the start of a truncated file
+)
+
+// Options controls how files are read.
+type Options struct {
+	MaxBytes int64  // bytes kept per file; larger files keep head and tail (0 = unlimited)
+	Binary   string // BinaryReject (default) or BinaryHexdump
+}
+
+// Result is a file decoded for a prompt.
+type Result struct {
+	Text      string
+	Size      int64  // size of the file on disk
+	Encoding  string // one of the Encoding constants
+	Truncated bool   // middle of the file was omitted
+}
+
+// Read returns the contents of path as UTF-8 text according to opts.
+func Read(path string, opts Options) (Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Result{}, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return Result{}, err
+	}
+	size := info.Size()
+
+	sniff := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, sniff)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return Result{}, err
+	}
+	sniff = sniff[:n]
+
+	enc := DetectEncoding(sniff)
+	if enc == EncodingBinary {
+		if opts.Binary != BinaryHexdump {
+			return Result{}, fmt.Errorf("%s: %w (%s, %s); set files.binary to %q to send a summary",
+				path, ErrBinary, http.DetectContentType(sniff), FormatSize(size), BinaryHexdump)
+		}
+		return Result{Text: hexSummary(sniff, size), Size: size, Encoding: enc}, nil
+	}
+
+	if opts.MaxBytes <= 0 || size <= opts.MaxBytes {
+		data, err := io.ReadAll(io.MultiReader(bytes.NewReader(sniff), f))
+		if err != nil {
+			return Result{}, err
+		}
+		return Result{Text: decode(data, enc), Size: size, Encoding: enc}, nil
+	}
+
+	headLen := opts.MaxBytes * headPercent / 100
+	tailLen := opts.MaxBytes - headLen
+	if enc == EncodingUTF16LE || enc == EncodingUTF16BE {
+		headLen &^= 1 // keep code units whole
+		tailLen &^= 1
+	}
+
+	head := make([]byte, headLen)
+	if _, err := f.ReadAt(head, 0); err != nil {
+		return Result{}, err
+	}
+	tail := make([]byte, tailLen)
+	if _, err := f.ReadAt(tail, size-tailLen); err != nil && !errors.Is(err, io.EOF) {
+		return Result{}, err
+	}
+
+	headText := decode(head, enc)
+	if i := strings.LastIndexByte(headText, '\n'); i > 0 {
+		headText = headText[:i+1]
+	}
+	tailText := decode(tail, enc)
+	if i := strings.IndexByte(tailText, '\n'); i >= 0 && i < len(tailText)-1 {
+		tailText = tailText[i+1:]
+	}
+
+	omitted := size - int64(len(head)) - int64(len(tail))
+	marker := fmt.Sprintf("\n[... truncated: %s of %s omitted; showing the first %s and last %s ...]\n\n",
+		FormatSize(omitted), FormatSize(size), FormatSize(headLen), FormatSize(tailLen))
+	return Result{Text: headText + marker + tailText, Size: size, Encoding: enc, Truncated: true}, nil
+}
+
+// DetectEncoding classifies a sample from the start of a file.
+func DetectEncoding(sample []byte) string {
+	switch {
+	case bytes.HasPrefix(sample, []byte{0xEF, 0xBB, 0xBF}):
+		return EncodingUTF8
+	case bytes.HasPrefix(sample, []byte{0xFF, 0xFE}):
+		return EncodingUTF16LE
+	case bytes.HasPrefix(sample, []byte{0xFE, 0xFF}):
+		return EncodingUTF16BE
+	}
+
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return utf16Guess(sample)
+	}
+
+	control := 0
+	for _, c := range sample {
+		if c < 0x20 && c != '\t' && c != '\n' && c != '\r' && c != '\f' && c != '\b' && c != 0x1B {
+			control++
+		}
+	}
+	if len(sample) > 0 && control*10 > len(sample) {
+		return EncodingBinary
+	}
+
+	if utf8.Valid(trimPartialRune(sample)) {
+		return EncodingUTF8
+	}
+	return EncodingLatin1
+}
+
+// utf16Guess detects BOM-less UTF-16 from the position of zero bytes, which
+// sit in the high byte of each ASCII code unit. Anything else with NULs is binary.
+func utf16Guess(sample []byte) string {
+	var even, odd int
+	for i, c := range sample {
+		if c != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			even++
+		} else {
+			odd++
+		}
+	}
+	units := len(sample) / 2
+	switch {
+	case units > 0 && odd*10 >= units*4 && even*20 < units:
+		return EncodingUTF16LE
+	case units > 0 && even*10 >= units*4 && odd*20 < units:
+		return EncodingUTF16BE
+	default:
+		return EncodingBinary
+	}
+}
+
+// trimPartialRune drops an incomplete UTF-8 sequence cut off at the end of b.
+func trimPartialRune(b []byte) []byte {
+	for i := 1; i <= utf8.UTFMax && i <= len(b); i++ {
+		if utf8.RuneStart(b[len(b)-i]) {
+			if !utf8.FullRune(b[len(b)-i:]) {
+				return b[:len(b)-i]
+			}
+			break
+		}
+	}
+	return b
+}
+
+// decode converts data in enc to UTF-8, dropping BOMs and invalid sequences
+// such as runes split by truncation.
+func decode(data []byte, enc string) string {
+	switch enc {
+	case EncodingUTF16LE, EncodingUTF16BE:
+		var order binary.ByteOrder = binary.LittleEndian
+		if enc == EncodingUTF16BE {
+			order = binary.BigEndian
+		}
+		units := make([]uint16, len(data)/2)
+		for i := range units {
+			units[i] = order.Uint16(data[2*i:])
+		}
+		s := string(utf16.Decode(units))
+		return strings.ToValidUTF8(strings.TrimPrefix(s, "\uFEFF"), "")
+	case EncodingLatin1:
+		runes := make([]rune, len(data))
+		for i, c := range data {
+			runes[i] = rune(c)
+		}
+		return string(runes)
+	default:
+		return strings.ToValidUTF8(strings.TrimPrefix(string(data), "\uFEFF"), "")
+	}
+}
+
+// hexSummary describes a binary file by size, detected type and leading bytes.
+func hexSummary(sniff []byte, size int64) string {
+	lead := sniff[:min(len(sniff), hexdumpLen)]
+	return fmt.Sprintf("[binary file: %s, detected %s; first %d bytes:]\n%s",
+		FormatSize(size), http.DetectContentType(sniff), len(lead), hex.Dump(lead))
+}
+
+// FormatSize renders a byte count as B, KB, MB or GB.
+func FormatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 2; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMG"[exp])
+}
@@ -0,0 +1,100 @@
+package textfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadTranscodes(t *testing.T) {
+	units := utf16.Encode([]rune("héllo wörld\n"))
+	le := []byte{0xFF, 0xFE}
+	bomless := []byte{}
+	for _, u := range units {
+		le = append(le, byte(u), byte(u>>8))
+		bomless = append(bomless, byte(u), byte(u>>8))
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		enc  string
+		want string
+	}{
+		{"utf8", []byte("\xEF\xBB\xBFplain ✓\n"), EncodingUTF8, "plain ✓\n"},
+		{"utf16-bom", le, EncodingUTF16LE, "héllo wörld\n"},
+		{"utf16-bomless", bomless, EncodingUTF16LE, "héllo wörld\n"},
+		{"latin1", []byte("caf\xe9 cr\xe8me\n"), EncodingLatin1, "café crème\n"},
+	}
+	for _, tt := range tests {
+		res, err := Read(writeFile(t, tt.name, tt.data), Options{})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if res.Encoding != tt.enc || res.Text != tt.want {
+			t.Errorf("%s: got %s %q, want %s %q", tt.name, res.Encoding, res.Text, tt.enc, tt.want)
+		}
+	}
+}
+
+func TestReadBinary(t *testing.T) {
+	path := writeFile(t, "blob.bin", []byte{0x7F, 'E', 'L', 'F', 2, 1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 3, 0})
+
+	if _, err := Read(path, Options{}); !errors.Is(err, ErrBinary) {
+		t.Fatalf("expected ErrBinary, got %v", err)
+	}
+
+	res, err := Read(path, Options{Binary: BinaryHexdump})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Encoding != EncodingBinary || !strings.Contains(res.Text, "binary file: 18 B") || !strings.Contains(res.Text, "7f 45 4c 46") {
+		t.Fatalf("unexpected summary: %q", res.Text)
+	}
+}
+
+func TestReadTruncatesHeadAndTail(t *testing.T) {
+	var b strings.Builder
+	for i := range 2000 {
+		b.WriteString(strings.Repeat("x", 40))
+		b.WriteString(" line ")
+		b.WriteString(string(rune('a' + i%26)))
+		b.WriteString("\n")
+	}
+	b.WriteString("THE END\n")
+	path := writeFile(t, "big.log", []byte("FIRST LINE\n"+b.String()))
+
+	res, err := Read(path, Options{MaxBytes: 4096})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Truncated || res.Size < 90_000 {
+		t.Fatalf("expected truncation of a ~95 KB file, got %+v", res.Size)
+	}
+	if !strings.HasPrefix(res.Text, "FIRST LINE\n") || !strings.HasSuffix(res.Text, "THE END\n") {
+		t.Fatal("expected head and tail to be kept")
+	}
+	if !strings.Contains(res.Text, "[... truncated:") || len(res.Text) > 4096+200 {
+		t.Fatalf("expected marker and bounded size, got %d bytes", len(res.Text))
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	for n, want := range map[int64]string{512: "512 B", 2048: "2.0 KB", 50 << 20: "50.0 MB", 3 << 30: "3.0 GB"} {
+		if got := FormatSize(n); got != want {
+			t.Errorf("FormatSize(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
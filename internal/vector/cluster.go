@@ -0,0 +1,254 @@
+package vector
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// KMeans groups unit-normalized vectors into k clusters by cosine similarity
+// (spherical k-means with k-means++ seeding). It returns a dense cluster id in
+// [0, k) for each vector. The seed makes results reproducible.
+func KMeans(vectors [][]float32, k, maxIter int, seed uint64) []int {
+	n := len(vectors)
+	assign := make([]int, n)
+	if n == 0 || k <= 1 {
+		return assign
+	}
+	k = min(k, n)
+	if maxIter <= 0 {
+		maxIter = 100
+	}
+
+	rng := rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15)) //nolint:gosec // G404: clustering does not need crypto rand
+	centroids := seedCentroids(vectors, k, rng)
+
+	for iter := range maxIter {
+		changed := false
+		for i, v := range vectors {
+			best, bestSim := 0, math.Inf(-1)
+			for c, centroid := range centroids {
+				if s := Dot(v, centroid); s > bestSim {
+					best, bestSim = c, s
+				}
+			}
+			if assign[i] != best {
+				assign[i] = best
+				changed = true
+			}
+		}
+		if iter > 0 && !changed {
+			break
+		}
+		centroids = recomputeCentroids(vectors, assign, centroids)
+	}
+	return denseLabels(assign)
+}
+
+// seedCentroids picks k initial centroids with k-means++ on cosine distance.
+func seedCentroids(vectors [][]float32, k int, rng *rand.Rand) [][]float32 {
+	centroids := [][]float32{cloneVec(vectors[rng.IntN(len(vectors))])}
+	dist := make([]float64, len(vectors))
+
+	for len(centroids) < k {
+		var total float64
+		for i, v := range vectors {
+			d := math.Inf(1)
+			for _, c := range centroids {
+				d = math.Min(d, 1-Dot(v, c))
+			}
+			d = math.Max(d, 0)
+			dist[i] = d * d
+			total += dist[i]
+		}
+		if total == 0 {
+			// All remaining points coincide with a centroid; pick any.
+			centroids = append(centroids, cloneVec(vectors[rng.IntN(len(vectors))]))
+			continue
+		}
+		target := rng.Float64() * total
+		pick := len(vectors) - 1
+		for i, d := range dist {
+			if target -= d; target <= 0 {
+				pick = i
+				break
+			}
+		}
+		centroids = append(centroids, cloneVec(vectors[pick]))
+	}
+	return centroids
+}
+
+func recomputeCentroids(vectors [][]float32, assign []int, prev [][]float32) [][]float32 {
+	dims := len(vectors[0])
+	sums := make([][]float64, len(prev))
+	counts := make([]int, len(prev))
+	for i := range sums {
+		sums[i] = make([]float64, dims)
+	}
+	for i, v := range vectors {
+		c := assign[i]
+		counts[c]++
+		for d := range min(dims, len(v)) {
+			sums[c][d] += float64(v[d])
+		}
+	}
+
+	out := make([][]float32, len(prev))
+	for c := range prev {
+		if counts[c] == 0 {
+			out[c] = prev[c] // keep empty clusters in place
+			continue
+		}
+		centroid := make([]float32, dims)
+		for d, s := range sums[c] {
+			centroid[d] = float32(s / float64(counts[c]))
+		}
+		out[c] = Normalize(centroid)
+	}
+	return out
+}
+
+// Agglomerative performs average-linkage hierarchical clustering on cosine
+// similarity. Merging stops when k clusters remain (k > 0) or when no pair of
+// clusters is more similar than threshold. Cluster ids are dense, in order of
+// first appearance.
+func Agglomerative(vectors [][]float32, k int, threshold float64) []int {
+	n := len(vectors)
+	if n == 0 {
+		return nil
+	}
+
+	sim := make([][]float64, n)
+	for i := range sim {
+		sim[i] = make([]float64, n)
+		for j := range i {
+			s := Cosine(vectors[i], vectors[j])
+			sim[i][j], sim[j][i] = s, s
+		}
+	}
+
+	parent := make([]int, n) // parent[i] = representative cluster of point i
+	size := make([]int, n)
+	active := make([]bool, n)
+	for i := range parent {
+		parent[i], size[i], active[i] = i, 1, true
+	}
+
+	// best[i] caches the most similar active neighbour of cluster i.
+	best := make([]int, n)
+	updateBest := func(i int) {
+		best[i] = -1
+		for j := range n {
+			if j != i && active[j] && (best[i] < 0 || sim[i][j] > sim[i][best[i]]) {
+				best[i] = j
+			}
+		}
+	}
+	for i := range n {
+		updateBest(i)
+	}
+
+	for clusters := n; clusters > 1 && (k <= 0 || clusters > k); clusters-- {
+		a := -1
+		for i := range n {
+			if active[i] && best[i] >= 0 && (a < 0 || sim[i][best[i]] > sim[a][best[a]]) {
+				a = i
+			}
+		}
+		if a < 0 {
+			break
+		}
+		b := best[a]
+		if k <= 0 && sim[a][b] < threshold {
+			break
+		}
+
+		// Merge b into a (Lance-Williams update for average linkage).
+		for j := range n {
+			if j == a || j == b || !active[j] {
+				continue
+			}
+			s := (float64(size[a])*sim[a][j] + float64(size[b])*sim[b][j]) / float64(size[a]+size[b])
+			sim[a][j], sim[j][a] = s, s
+		}
+		size[a] += size[b]
+		active[b] = false
+		for i := range parent {
+			if parent[i] == b {
+				parent[i] = a
+			}
+		}
+
+		for j := range n {
+			if !active[j] || j == a {
+				continue
+			}
+			if best[j] == a || best[j] == b {
+				updateBest(j)
+			} else if sim[j][a] > sim[j][best[j]] {
+				best[j] = a
+			}
+		}
+		updateBest(a)
+	}
+
+	return denseLabels(parent)
+}
+
+// DedupeGroups assigns near-duplicate vectors to the same group: each vector
+// joins the first earlier group whose representative has cosine similarity
+// >= threshold, otherwise it starts a new group. Group ids are dense and the
+// representative of each group is its first member.
+func DedupeGroups(vectors [][]float32, threshold float64) []int {
+	groups := make([]int, len(vectors))
+	var reps []int
+	for i, v := range vectors {
+		groups[i] = -1
+		for g, r := range reps {
+			if Cosine(v, vectors[r]) >= threshold {
+				groups[i] = g
+				break
+			}
+		}
+		if groups[i] < 0 {
+			groups[i] = len(reps)
+			reps = append(reps, i)
+		}
+	}
+	return groups
+}
+
+// Mean returns the element-wise mean of vectors.
+func Mean(vectors [][]float32) []float32 {
+	if len(vectors) == 0 {
+		return nil
+	}
+	out := make([]float32, len(vectors[0]))
+	for _, v := range vectors {
+		for d := range min(len(out), len(v)) {
+			out[d] += v[d]
+		}
+	}
+	for d := range out {
+		out[d] /= float32(len(vectors))
+	}
+	return out
+}
+
+func denseLabels(raw []int) []int {
+	ids := map[int]int{}
+	out := make([]int, len(raw))
+	for i, r := range raw {
+		id, ok := ids[r]
+		if !ok {
+			id = len(ids)
+			ids[r] = id
+		}
+		out[i] = id
+	}
+	return out
+}
+
+func cloneVec(v []float32) []float32 {
+	return append([]float32(nil), v...)
+}
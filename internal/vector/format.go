@@ -0,0 +1,211 @@
+package vector
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Output formats accepted by Write.
+const (
+	FormatJSONL = "jsonl"
+	FormatCSV   = "csv"
+	FormatNPY   = "npy"
+	FormatBin   = "bin"
+)
+
+// Formats lists the supported output formats.
+var Formats = []string{FormatJSONL, FormatCSV, FormatNPY, FormatBin} //nolint:gochecknoglobals // read-only lookup table
+
+// binMagic identifies the syn columnar vector format.
+const binMagic = "SYNVEC1\n"
+
+// Row is one embedded input ready for export.
+type Row struct {
+	ID     string    `json:"id"`
+	Text   string    `json:"text"`
+	Vector []float64 `json:"vector"`
+}
+
+// WriteOptions controls numeric precision of written vectors.
+type WriteOptions struct {
+	Float32 bool // downcast elements to float32
+}
+
+// IsBinary reports whether format produces non-text output.
+func IsBinary(format string) bool {
+	return format == FormatNPY || format == FormatBin
+}
+
+// Write encodes rows to w in the given format.
+func Write(w io.Writer, format string, rows []Row, opts WriteOptions) error {
+	switch format {
+	case FormatJSONL:
+		return WriteJSONL(w, rows, opts)
+	case FormatCSV:
+		return WriteCSV(w, rows, opts)
+	case FormatNPY:
+		return WriteNPY(w, rows, opts)
+	case FormatBin:
+		return WriteBin(w, rows, opts)
+	default:
+		return fmt.Errorf("unknown format %q (expected %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// WriteJSONL writes one {"id","text","vector"} object per line.
+func WriteJSONL(w io.Writer, rows []Row, opts WriteOptions) error {
+	bw := bufio.NewWriter(w)
+	for _, r := range rows {
+		id, _ := json.Marshal(r.ID)
+		text, _ := json.Marshal(r.Text)
+		fmt.Fprintf(bw, `{"id":%s,"text":%s,"vector":%s}`+"\n", id, text, formatVector(r.Vector, opts))
+	}
+	return bw.Flush()
+}
+
+// WriteCSV writes an id,text,vector header followed by one row per input.
+// Vectors use pgvector's text form ("[0.1,0.2,...]") for direct COPY imports.
+func WriteCSV(w io.Writer, rows []Row, opts WriteOptions) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"id", "text", "vector"}); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if err := cw.Write([]string{r.ID, r.Text, formatVector(r.Vector, opts)}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteNPY writes the vectors as a 2-D little-endian NumPy array (format v1.0).
+// Row order matches rows; ids and texts are not stored.
+func WriteNPY(w io.Writer, rows []Row, opts WriteOptions) error {
+	dims, err := uniformDims(rows)
+	if err != nil {
+		return err
+	}
+
+	descr := "<f8"
+	if opts.Float32 {
+		descr = "<f4"
+	}
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': (%d, %d), }", descr, len(rows), dims)
+
+	// Pad so magic(6) + version(2) + length(2) + header is a multiple of 64, ending in '\n'.
+	total := 10 + len(header) + 1
+	header += strings.Repeat(" ", (64-total%64)%64) + "\n"
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("\x93NUMPY\x01\x00")
+	if err := binary.Write(bw, binary.LittleEndian, uint16(len(header))); err != nil { //nolint:gosec // G115: header is a few hundred bytes
+		return err
+	}
+	bw.WriteString(header)
+	if err := writeElements(bw, rows, opts); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// WriteBin writes a columnar binary file: a fixed header, the row-major vector
+// matrix, then length-prefixed id and text columns. All integers are
+// little-endian uint32.
+//
+//	magic "SYNVEC1\n" | rows | dims | elem_size (4 or 8)
+//	vectors [rows*dims]float
+//	ids     rows * (len, bytes)
+//	texts   rows * (len, bytes)
+func WriteBin(w io.Writer, rows []Row, opts WriteOptions) error {
+	dims, err := uniformDims(rows)
+	if err != nil {
+		return err
+	}
+	elemSize := uint32(8)
+	if opts.Float32 {
+		elemSize = 4
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(binMagic)
+	for _, v := range []uint32{uint32(len(rows)), uint32(dims), elemSize} { //nolint:gosec // G115: row and dimension counts fit in uint32
+		if err := binary.Write(bw, binary.LittleEndian, v); err != nil {
+			return err
+		}
+	}
+	if err := writeElements(bw, rows, opts); err != nil {
+		return err
+	}
+	for _, column := range []func(Row) string{
+		func(r Row) string { return r.ID },
+		func(r Row) string { return r.Text },
+	} {
+		for _, r := range rows {
+			s := column(r)
+			if err := binary.Write(bw, binary.LittleEndian, uint32(len(s))); err != nil { //nolint:gosec // G115: string length fits in uint32
+				return err
+			}
+			bw.WriteString(s)
+		}
+	}
+	return bw.Flush()
+}
+
+func writeElements(w io.Writer, rows []Row, opts WriteOptions) error {
+	buf := make([]byte, 8)
+	for _, r := range rows {
+		for _, x := range r.Vector {
+			if opts.Float32 {
+				binary.LittleEndian.PutUint32(buf, math.Float32bits(float32(x)))
+				if _, err := w.Write(buf[:4]); err != nil {
+					return err
+				}
+				continue
+			}
+			binary.LittleEndian.PutUint64(buf, math.Float64bits(x))
+			if _, err := w.Write(buf); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func uniformDims(rows []Row) (int, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	dims := len(rows[0].Vector)
+	for _, r := range rows {
+		if len(r.Vector) != dims {
+			return 0, fmt.Errorf("row %s has %d dimensions, expected %d", r.ID, len(r.Vector), dims)
+		}
+	}
+	return dims, nil
+}
+
+// formatVector renders v as "[x,y,...]" at the requested precision.
+func formatVector(v []float64, opts WriteOptions) string {
+	bitSize := 64
+	if opts.Float32 {
+		bitSize = 32
+	}
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, x := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(x, 'g', -1, bitSize))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
@@ -0,0 +1,63 @@
+package vector
+
+import "math"
+
+// Float is the element type of a vector.
+type Float interface {
+	~float32 | ~float64
+}
+
+// FromFloat64 converts an API embedding to float32 storage precision.
+func FromFloat64(v []float64) []float32 {
+	out := make([]float32, len(v))
+	for i, x := range v {
+		out[i] = float32(x)
+	}
+	return out
+}
+
+// Dot returns the dot product of a and b over their common length.
+func Dot[T Float](a, b []T) float64 {
+	n := min(len(a), len(b))
+	var sum float64
+	for i := range n {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+// Norm returns the L2 norm of v.
+func Norm[T Float](v []T) float64 {
+	return math.Sqrt(Dot(v, v))
+}
+
+// Cosine returns the cosine similarity of a and b, or 0 if either is a zero vector.
+func Cosine[T Float](a, b []T) float64 {
+	na, nb := Norm(a), Norm(b)
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return Dot(a, b) / (na * nb)
+}
+
+// Normalize scales v to unit L2 length in place and returns it.
+// Zero vectors are returned unchanged.
+func Normalize[T Float](v []T) []T {
+	n := Norm(v)
+	if n == 0 {
+		return v
+	}
+	for i := range v {
+		v[i] = T(float64(v[i]) / n)
+	}
+	return v
+}
+
+// Truncate returns the first dims components of v (Matryoshka-style
+// truncation). A dims <= 0 or >= len(v) returns v unchanged.
+func Truncate[T Float](v []T, dims int) []T {
+	if dims <= 0 || dims >= len(v) {
+		return v
+	}
+	return v[:dims]
+}
@@ -0,0 +1,133 @@
+package vector
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestCosineAndNormalize(t *testing.T) {
+	if got := Cosine([]float32{1, 0}, []float32{0, 1}); got != 0 {
+		t.Fatalf("expected orthogonal cosine 0, got %f", got)
+	}
+	if got := Cosine([]float64{1, 2}, []float64{2, 4}); math.Abs(got-1) > 1e-9 {
+		t.Fatalf("expected parallel cosine 1, got %f", got)
+	}
+
+	v := Normalize(Truncate([]float64{3, 4, 12}, 2))
+	if math.Abs(v[0]-0.6) > 1e-9 || math.Abs(v[1]-0.8) > 1e-9 {
+		t.Fatalf("unexpected normalized vector: %v", v)
+	}
+}
+
+func testRows() []Row {
+	return []Row{
+		{ID: "0", Text: `say "hi", ok`, Vector: []float64{0.5, -1}},
+		{ID: "1", Text: "second", Vector: []float64{0.1, 2}},
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	var jsonl bytes.Buffer
+	if err := WriteJSONL(&jsonl, testRows(), WriteOptions{Float32: true}); err != nil {
+		t.Fatal(err)
+	}
+	first := strings.SplitN(jsonl.String(), "\n", 2)[0]
+	if first != `{"id":"0","text":"say \"hi\", ok","vector":[0.5,-1]}` {
+		t.Fatalf("unexpected jsonl line: %s", first)
+	}
+
+	var csvOut bytes.Buffer
+	if err := WriteCSV(&csvOut, testRows(), WriteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(csvOut.String(), `0,"say ""hi"", ok","[0.5,-1]"`) {
+		t.Fatalf("unexpected csv output: %s", csvOut.String())
+	}
+}
+
+func TestWriteNPY(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteNPY(&buf, testRows(), WriteOptions{Float32: true}); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if !bytes.HasPrefix(data, []byte("\x93NUMPY\x01\x00")) {
+		t.Fatalf("missing npy magic")
+	}
+	headerLen := int(binary.LittleEndian.Uint16(data[8:10]))
+	if (10+headerLen)%64 != 0 {
+		t.Fatalf("npy header not 64-byte aligned: %d", 10+headerLen)
+	}
+	header := string(data[10 : 10+headerLen])
+	if !strings.Contains(header, "'<f4'") || !strings.Contains(header, "(2, 2)") {
+		t.Fatalf("unexpected npy header: %q", header)
+	}
+	if got := len(data) - 10 - headerLen; got != 2*2*4 {
+		t.Fatalf("expected 16 bytes of float32 data, got %d", got)
+	}
+}
+
+func TestWriteBin(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteBin(&buf, testRows(), WriteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if string(data[:8]) != binMagic {
+		t.Fatalf("missing bin magic")
+	}
+	rows := binary.LittleEndian.Uint32(data[8:])
+	dims := binary.LittleEndian.Uint32(data[12:])
+	elem := binary.LittleEndian.Uint32(data[16:])
+	if rows != 2 || dims != 2 || elem != 8 {
+		t.Fatalf("unexpected header rows=%d dims=%d elem=%d", rows, dims, elem)
+	}
+	first := math.Float64frombits(binary.LittleEndian.Uint64(data[20:]))
+	if first != 0.5 {
+		t.Fatalf("expected first element 0.5, got %f", first)
+	}
+
+	if err := WriteBin(&buf, []Row{{Vector: []float64{1}}, {Vector: []float64{1, 2}}}, WriteOptions{}); err == nil {
+		t.Fatalf("expected ragged dimensions error")
+	}
+}
+
+func twoBlobs() [][]float32 {
+	return [][]float32{
+		Normalize([]float32{1, 0.05, 0}),
+		Normalize([]float32{0, 1, 0.02}),
+		Normalize([]float32{1, 0, 0.05}),
+		Normalize([]float32{0.04, 1, 0}),
+		Normalize([]float32{1, 0.02, 0.02}),
+	}
+}
+
+func TestKMeans(t *testing.T) {
+	assign := KMeans(twoBlobs(), 2, 50, 7)
+	if assign[0] != assign[2] || assign[0] != assign[4] || assign[1] != assign[3] || assign[0] == assign[1] {
+		t.Fatalf("unexpected k-means assignment: %v", assign)
+	}
+}
+
+func TestAgglomerative(t *testing.T) {
+	byK := Agglomerative(twoBlobs(), 2, 0)
+	byThreshold := Agglomerative(twoBlobs(), 0, 0.9)
+	for _, assign := range [][]int{byK, byThreshold} {
+		if assign[0] != 0 || assign[1] != 1 || assign[2] != 0 || assign[3] != 1 || assign[4] != 0 {
+			t.Fatalf("unexpected agglomerative assignment: %v", assign)
+		}
+	}
+}
+
+func TestDedupeGroups(t *testing.T) {
+	groups := DedupeGroups(twoBlobs(), 0.99)
+	want := []int{0, 1, 0, 1, 0}
+	for i := range want {
+		if groups[i] != want[i] {
+			t.Fatalf("DedupeGroups() = %v, want %v", groups, want)
+		}
+	}
+}